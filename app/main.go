@@ -3,11 +3,13 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
 	"github/goInterpreter/lexer"
+	"github/goInterpreter/parser/exprVisitors"
 	"github/goInterpreter/runner"
 )
 
@@ -17,22 +19,80 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Usage: ./your_program.sh tokenize\n")
 		fmt.Fprintf(os.Stderr, "Usage: ./your_program.sh parse <filename>\n")
 		fmt.Fprintf(os.Stderr, "Usage: ./your_program.sh parse\n")
+		fmt.Fprintf(os.Stderr, "Usage: ./your_program.sh parse --format=dot [--annotate [--numeric=strict]] <filename>\n")
 		fmt.Fprintf(os.Stderr, "Usage: ./your_program.sh evaluate\n")
 		fmt.Fprintf(os.Stderr, "Usage: ./your_program.sh evaluate <filename>\n")
+		fmt.Fprintf(os.Stderr, "Usage: ./your_program.sh evaluate --numeric=strict <filename>\n")
+		fmt.Fprintf(os.Stderr, "Usage: ./your_program.sh fmt [-w] [-d] <filename>\n")
 		os.Exit(1)
 	}
 	command := os.Args[1]
-	if len(os.Args) == 2 {
+	// every command has a flag set of its own so that it rejects the flags of the others
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	format, annotate, numericFlag := new(string), new(bool), new(string)
+	write, diff := new(bool), new(bool)
+	*format, *numericFlag = "sexpr", "ieee"
+	switch command {
+	case "tokenize":
+	case "parse":
+		format = flags.String("format", "sexpr", "output format: sexpr or dot")
+		annotate = flags.Bool("annotate", false, "annotate dot nodes with their evaluated values")
+		numericFlag = flags.String("numeric", "ieee", "numeric policy of --annotate: ieee or strict")
+	case "evaluate":
+		numericFlag = flags.String("numeric", "ieee", "numeric policy: ieee or strict")
+	case "fmt":
+		write = flags.Bool("w", false, "write the result back to the source file")
+		diff = flags.Bool("d", false, "print a diff instead of the formatted source")
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+		os.Exit(1)
+	}
+	flags.Parse(os.Args[2:])
+	if flags.NArg() > 1 {
+		// flag stops at the first argument that is not a flag, so a flag after
+		// the file name would otherwise be ignored
+		if strings.HasPrefix(flags.Arg(1), "-") {
+			fmt.Fprintf(os.Stderr, "Flags must come before the file name: %s\n", flags.Arg(1))
+		} else {
+			fmt.Fprintf(os.Stderr, "Unexpected argument: %s\n", flags.Arg(1))
+		}
+		os.Exit(1)
+	}
+	if *format != "sexpr" && *format != "dot" {
+		fmt.Fprintf(os.Stderr, "Unknown format: %s\n", *format)
+		os.Exit(1)
+	}
+	if *annotate && *format != "dot" {
+		fmt.Fprintf(os.Stderr, "--annotate requires --format=dot\n")
+		os.Exit(1)
+	}
+	if command == "parse" && *numericFlag != "ieee" && !*annotate {
+		fmt.Fprintf(os.Stderr, "--numeric requires --annotate\n")
+		os.Exit(1)
+	}
+	var numeric exprVisitors.NumericPolicy
+	switch *numericFlag {
+	case "ieee":
+		numeric = exprVisitors.NumericIEEE
+	case "strict":
+		numeric = exprVisitors.NumericStrict
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown numeric policy: %s\n", *numericFlag)
+		os.Exit(1)
+	}
+	if flags.NArg() == 0 {
 		if command == "tokenize" {
 			replTokenize()
 		} else if command == "parse" {
-			replParse()
+			replParse(*format, *annotate, numeric)
 		} else if command == "evaluate" {
-			replEvaluate()
+			replEvaluate(numeric)
+		} else if command == "fmt" {
+			replFormat()
 		}
 		os.Exit(0)
 	}
-	fileName := os.Args[2]
+	fileName := flags.Arg(0)
 	fileContents, err := os.ReadFile(fileName)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening file %s: %s", fileName, err)
@@ -44,8 +104,15 @@ func main() {
 			Reader: r,
 			Line:   1,
 			Lexeme: bytes.NewBuffer(nil),
+			Trivia: command == "fmt",
 		}
 		lexRes := runner.RunLexer(&lex)
+		if command != "tokenize" && lexRes.ExitCode != 0 {
+			// a malformed token is left out of the token stream, so the parser
+			// would only report the gap it leaves
+			lexRes.PrintErrors()
+			os.Exit(lexRes.ExitCode)
+		}
 		switch command {
 		case "tokenize":
 			lexRes.Print()
@@ -55,21 +122,46 @@ func main() {
 			if parseRes.ExitCode != 0 {
 				os.Exit(parseRes.ExitCode)
 			}
-			parseRes.Print()
+			if *format == "dot" {
+				parseRes.PrintDot(*annotate, numeric)
+			} else {
+				parseRes.Print()
+			}
 			os.Exit(parseRes.ExitCode)
 		case "evaluate":
 			parseRes := runner.RunParser(lexRes.Tokens)
 			if parseRes.ExitCode != 0 {
 				os.Exit(parseRes.ExitCode)
 			}
-			evalRes := runner.RunEvaluator(parseRes.Expr)
+			evalRes := runner.RunEvaluator(parseRes.Expr, numeric)
 			evalRes.Print()
 			os.Exit(evalRes.ExitCode)
-		default:
-			fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
-			os.Exit(1)
+		case "fmt":
+			parseRes := runner.RunParser(lexRes.Tokens)
+			if parseRes.ExitCode != 0 {
+				// parse errors have already been logged
+				os.Exit(parseRes.ExitCode)
+			}
+			fmtRes := runner.RunFormatter(parseRes.Expr, lexRes.Tokens)
+			if fmtRes.ExitCode != 0 {
+				os.Exit(fmtRes.ExitCode)
+			}
+			if *diff {
+				fmt.Print(runner.UnifiedDiff(fileName, string(fileContents), fmtRes.Source))
+			}
+			if *write {
+				if err := os.WriteFile(fileName, []byte(fmtRes.Source), 0644); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing file %s: %s", fileName, err)
+					os.Exit(1)
+				}
+			}
+			if !*diff && !*write {
+				fmtRes.Print()
+			}
+			os.Exit(fmtRes.ExitCode)
 		}
-	} else {
+	} else if command != "fmt" {
+		// fmt leaves an empty file as it is, with nothing to print, diff or write
 		fmt.Println("EOF  null")
 	}
 
@@ -103,7 +195,7 @@ func replTokenize() {
 	}
 }
 
-func replParse() {
+func replParse(format string, annotate bool, numeric exprVisitors.NumericPolicy) {
 	scanner := bufio.NewScanner(os.Stdin)
 	for {
 		fmt.Fprintf(os.Stdout, "> ")
@@ -127,15 +219,23 @@ func replParse() {
 			Lexeme: bytes.NewBuffer(nil),
 		}
 		runRes := runner.RunLexer(&lex)
+		if runRes.ExitCode != 0 {
+			runRes.PrintErrors()
+			continue
+		}
 		parseRes := runner.RunParser(runRes.Tokens)
 		if parseRes.ExitCode == 0 {
-			parseRes.Print()
+			if format == "dot" {
+				parseRes.PrintDot(annotate, numeric)
+			} else {
+				parseRes.Print()
+			}
 		}
 	}
 
 }
 
-func replEvaluate() {
+func replEvaluate(numeric exprVisitors.NumericPolicy) {
 	scanner := bufio.NewScanner(os.Stdin)
 	for {
 		fmt.Fprintf(os.Stdout, "> ")
@@ -159,10 +259,51 @@ func replEvaluate() {
 			Lexeme: bytes.NewBuffer(nil),
 		}
 		runRes := runner.RunLexer(lex)
+		if runRes.ExitCode != 0 {
+			runRes.PrintErrors()
+			continue
+		}
 		parseRes := runner.RunParser(runRes.Tokens)
-		evalRes := runner.RunEvaluator(parseRes.Expr)
+		evalRes := runner.RunEvaluator(parseRes.Expr, numeric)
 		if evalRes.ExitCode == 0 {
 			evalRes.Print()
 		}
 	}
 }
+
+func replFormat() {
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Fprintf(os.Stdout, "> ")
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error occurred reading line: %s", err.Error())
+				continue
+			}
+			// if scanner.Scan() == false but no error occurred it means the user hit control + D
+			break
+		}
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineBytes := []byte(line)
+		r := bytes.NewReader(lineBytes)
+		lex := &lexer.Lexer{
+			Reader: r,
+			Line:   1,
+			Lexeme: bytes.NewBuffer(nil),
+			Trivia: true,
+		}
+		runRes := runner.RunLexer(lex)
+		if runRes.ExitCode != 0 {
+			runRes.PrintErrors()
+			continue
+		}
+		parseRes := runner.RunParser(runRes.Tokens)
+		if parseRes.ExitCode == 0 {
+			fmtRes := runner.RunFormatter(parseRes.Expr, runRes.Tokens)
+			fmtRes.Print()
+		}
+	}
+}
@@ -18,6 +18,23 @@ type UnterminatedStringError struct {
 	Message string
 	Line    int
 }
+type InvalidEscapeError struct {
+	Message  string
+	Sequence string
+	Line     int
+	Column   int
+}
+type MalformedNumberError struct {
+	Message string
+	Lexeme  string
+	Reason  string
+	Line    int
+	Column  int
+}
+type UnterminatedBlockCommentError struct {
+	Message string
+	Line    int
+}
 
 func (uc UnrecognizedCharError) FormatMessage() string {
 	return fmt.Sprintf("[line %s] Error: Unexpected character: %s\n", strconv.Itoa(uc.Line), uc.Char)
@@ -27,6 +44,18 @@ func (us UnterminatedStringError) FormatMessage() string {
 	return fmt.Sprintf("[line %s] Error: Unterminated string.", strconv.Itoa(us.Line))
 }
 
+func (ie InvalidEscapeError) FormatMessage() string {
+	return fmt.Sprintf("[line %s] Error at column %s: Invalid escape sequence: %s\n", strconv.Itoa(ie.Line), strconv.Itoa(ie.Column), ie.Sequence)
+}
+
+func (mn MalformedNumberError) FormatMessage() string {
+	return fmt.Sprintf("[line %s] Error at column %s: Malformed number literal %s: %s\n", strconv.Itoa(mn.Line), strconv.Itoa(mn.Column), mn.Lexeme, mn.Reason)
+}
+
+func (ub UnterminatedBlockCommentError) FormatMessage() string {
+	return fmt.Sprintf("[line %s] Error: Unterminated block comment.", strconv.Itoa(ub.Line))
+}
+
 type TokenErrors []TokenError
 
 func (te TokenErrors) ToString() string {
@@ -6,35 +6,58 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 type Lexer struct {
 	Reader *bytes.Reader
 	Line   int
 	Lexeme *bytes.Buffer
+	// when Trivia is set the whitespace and comments around every token are
+	// kept on the token instead of being discarded
+	Trivia    bool
+	leading   []Trivia
+	lineStart int64
 }
 
 var keywords = map[string]TokenType{
-	"and":    TokenAnd,
-	"class":  TokenClass,
-	"else":   TokenElse,
-	"false":  TokenFalse,
-	"for":    TokenFor,
-	"fun":    TokenFun,
-	"if":     TokenIf,
-	"nil":    TokenNil,
-	"or":     TokenOr,
-	"print":  TokenPrint,
-	"return": TokenReturn,
-	"super":  TokenSuper,
-	"this":   TokenThis,
-	"true":   TokenTrue,
-	"var":    TokenVar,
-	"while":  TokenWhile,
+	"and":      TokenAnd,
+	"break":    TokenBreak,
+	"case":     TokenCase,
+	"class":    TokenClass,
+	"continue": TokenContinue,
+	"else":     TokenElse,
+	"false":    TokenFalse,
+	"for":      TokenFor,
+	"fun":      TokenFun,
+	"if":       TokenIf,
+	"match":    TokenMatch,
+	"nil":      TokenNil,
+	"or":       TokenOr,
+	"print":    TokenPrint,
+	"return":   TokenReturn,
+	"super":    TokenSuper,
+	"this":     TokenThis,
+	"true":     TokenTrue,
+	"var":      TokenVar,
+	"while":    TokenWhile,
 }
 
 // Emit a Token by reading the next rune from the bytes.Reader object stored in Lexer
 func (l *Lexer) NextToken() (Token, TokenError, error) {
+	tok, tokErr, err := l.nextToken()
+	if !l.Trivia || err != nil || tokErr != nil {
+		return tok, tokErr, err
+	}
+	tok.Leading = l.takeLeading()
+	tok.Trailing, err = l.scanTrailing()
+	if err != nil {
+		return Token{}, nil, err
+	}
+	return tok, nil, nil
+}
+
+func (l *Lexer) nextToken() (Token, TokenError, error) {
 	r := l.Reader
 	c, _, err := r.ReadRune()
 	if err != nil {
@@ -70,34 +93,128 @@ func (l *Lexer) NextToken() (Token, TokenError, error) {
 			Literal: "null",
 			Line:    l.Line,
 		}, nil, nil
-	case c == '.':
+	case c == '[':
 		return Token{
-			Type:    TokenDot,
-			Lexeme:  ".",
+			Type:    TokenLeftBracket,
+			Lexeme:  "[",
 			Literal: "null",
 			Line:    l.Line,
 		}, nil, nil
-	case c == ',':
+	case c == ']':
 		return Token{
-			Type:    TokenComma,
-			Lexeme:  ",",
+			Type:    TokenRightBracket,
+			Lexeme:  "]",
 			Literal: "null",
 			Line:    l.Line,
 		}, nil, nil
-	case c == '-':
+	case c == '.':
+		next, after, err := l.peekTwoRunes()
+		if err != nil {
+			return Token{}, nil, err
+		}
+		if next == '.' && after == '.' {
+			l.acceptRune()
+			l.acceptRune()
+			return Token{
+				Type:    TokenEllipsis,
+				Lexeme:  "...",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		}
 		return Token{
-			Type:    TokenMinus,
-			Lexeme:  "-",
+			Type:    TokenDot,
+			Lexeme:  ".",
 			Literal: "null",
 			Line:    l.Line,
 		}, nil, nil
-	case c == '+':
+	case c == ',':
 		return Token{
-			Type:    TokenPlus,
-			Lexeme:  "+",
+			Type:    TokenComma,
+			Lexeme:  ",",
 			Literal: "null",
 			Line:    l.Line,
 		}, nil, nil
+	case c == '-':
+		cn, _, err := r.ReadRune()
+		if err == io.EOF {
+			return Token{
+				Type:    TokenMinus,
+				Lexeme:  "-",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		}
+		if err != nil {
+			return Token{}, nil, err
+		}
+		switch cn {
+		case '-':
+			return Token{
+				Type:    TokenMinusMinus,
+				Lexeme:  "--",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		case '=':
+			return Token{
+				Type:    TokenMinusEqual,
+				Lexeme:  "-=",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		default:
+			err := r.UnreadRune()
+			if err != nil {
+				return Token{}, nil, err
+			}
+			return Token{
+				Type:    TokenMinus,
+				Lexeme:  "-",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		}
+	case c == '+':
+		cn, _, err := r.ReadRune()
+		if err == io.EOF {
+			return Token{
+				Type:    TokenPlus,
+				Lexeme:  "+",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		}
+		if err != nil {
+			return Token{}, nil, err
+		}
+		switch cn {
+		case '+':
+			return Token{
+				Type:    TokenPlusPlus,
+				Lexeme:  "++",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		case '=':
+			return Token{
+				Type:    TokenPlusEqual,
+				Lexeme:  "+=",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		default:
+			err := r.UnreadRune()
+			if err != nil {
+				return Token{}, nil, err
+			}
+			return Token{
+				Type:    TokenPlus,
+				Lexeme:  "+",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		}
 	case c == '=':
 		cn, _, err := r.ReadRune()
 		if err == io.EOF {
@@ -119,6 +236,13 @@ func (l *Lexer) NextToken() (Token, TokenError, error) {
 				Literal: "null",
 				Line:    l.Line,
 			}, nil, nil
+		case '>':
+			return Token{
+				Type:    TokenArrow,
+				Lexeme:  "=>",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
 		default:
 			err := r.UnreadRune()
 			if err != nil {
@@ -185,6 +309,13 @@ func (l *Lexer) NextToken() (Token, TokenError, error) {
 				Literal: "null",
 				Line:    l.Line,
 			}, nil, nil
+		case '<':
+			return Token{
+				Type:    TokenLessLess,
+				Lexeme:  "<<",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
 		default:
 			err := r.UnreadRune()
 			if err != nil {
@@ -218,6 +349,13 @@ func (l *Lexer) NextToken() (Token, TokenError, error) {
 				Literal: "null",
 				Line:    l.Line,
 			}, nil, nil
+		case '>':
+			return Token{
+				Type:    TokenGreaterGreater,
+				Lexeme:  ">>",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
 		default:
 			err := r.UnreadRune()
 			if err != nil {
@@ -230,6 +368,68 @@ func (l *Lexer) NextToken() (Token, TokenError, error) {
 				Line:    l.Line,
 			}, nil, nil
 		}
+	case c == '%':
+		return Token{
+			Type:    TokenPercent,
+			Lexeme:  "%",
+			Literal: "null",
+			Line:    l.Line,
+		}, nil, nil
+	case c == '&':
+		return Token{
+			Type:    TokenAmpersand,
+			Lexeme:  "&",
+			Literal: "null",
+			Line:    l.Line,
+		}, nil, nil
+	case c == '|':
+		return Token{
+			Type:    TokenPipe,
+			Lexeme:  "|",
+			Literal: "null",
+			Line:    l.Line,
+		}, nil, nil
+	case c == '^':
+		return Token{
+			Type:    TokenCaret,
+			Lexeme:  "^",
+			Literal: "null",
+			Line:    l.Line,
+		}, nil, nil
+	case c == '~':
+		cn, _, err := r.ReadRune()
+		if err == io.EOF {
+			return Token{
+				Type:    TokenTilde,
+				Lexeme:  "~",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		}
+		if err != nil {
+			return Token{}, nil, err
+		}
+		switch cn {
+		case '/':
+			// floor division, spelled ~/ because // starts a comment
+			return Token{
+				Type:    TokenTildeSlash,
+				Lexeme:  "~/",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		default:
+			err := r.UnreadRune()
+			if err != nil {
+				return Token{}, nil, err
+			}
+			return Token{
+				Type:    TokenTilde,
+				Lexeme:  "~",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		}
 	case c == ':':
 		return Token{
 			Type:    TokenColon,
@@ -238,6 +438,29 @@ func (l *Lexer) NextToken() (Token, TokenError, error) {
 			Line:    l.Line,
 		}, nil, nil
 	case c == '?':
+		next, err := l.peekRune()
+		if err != nil && err != io.EOF {
+			return Token{}, nil, err
+		}
+		switch next {
+		case '?':
+			l.acceptRune()
+			return Token{
+				Type:    TokenQuestionQuestion,
+				Lexeme:  "??",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		case '.':
+			// numbers never start with a dot so "?." cannot be a ternary
+			l.acceptRune()
+			return Token{
+				Type:    TokenQuestionDot,
+				Lexeme:  "?.",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		}
 		return Token{
 			Type:    TokenQuestionMark,
 			Lexeme:  "?",
@@ -267,20 +490,66 @@ func (l *Lexer) NextToken() (Token, TokenError, error) {
 		switch cn {
 		case '/':
 			// single-line comment
+			comment := []rune{'/', '/'}
 			for {
 				// consume until '\n' or EOF
 				nxt, _, err := r.ReadRune()
 				if nxt == '\n' {
 					// if end of line
-					l.Line++
-					return l.NextToken()
+					l.addLeading(TriviaComment, string(comment))
+					l.addLeading(TriviaNewline, "\n")
+					l.newLine()
+					return l.nextToken()
 				}
 				if err != nil {
 					// or err occurs including io.EOF -> don't yield any tokens because
 					// we were in a comment state
+					l.addLeading(TriviaComment, string(comment))
 					return Token{}, nil, err
 				}
+				comment = append(comment, nxt)
 			}
+		case '=':
+			return Token{
+				Type:    TokenSlashEqual,
+				Lexeme:  "/=",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
+		case '*':
+			// block comment, which may nest
+			startLine := l.Line
+			comment := []rune{'/', '*'}
+			depth := 1
+			// prev is reset after a delimiter so "/*/" or "*/*" is not read as two
+			var prev rune
+			for depth > 0 {
+				nxt, _, err := r.ReadRune()
+				if err == io.EOF {
+					ub := UnterminatedBlockCommentError{
+						Line: startLine,
+					}
+					ub.Message = ub.FormatMessage()
+					return Token{}, ub, nil
+				}
+				if err != nil {
+					return Token{}, nil, err
+				}
+				comment = append(comment, nxt)
+				switch {
+				case prev == '*' && nxt == '/':
+					depth--
+					nxt = 0
+				case prev == '/' && nxt == '*':
+					depth++
+					nxt = 0
+				case nxt == '\n':
+					l.newLine()
+				}
+				prev = nxt
+			}
+			l.addLeading(TriviaComment, string(comment))
+			return l.nextToken()
 		default:
 			err := r.UnreadRune()
 			if err != nil {
@@ -309,12 +578,30 @@ func (l *Lexer) NextToken() (Token, TokenError, error) {
 		}
 		switch cn {
 		case '*':
+			if next, err := l.peekRune(); err == nil && next == '=' {
+				l.acceptRune()
+				return Token{
+					Type:    TokenStarStarEqual,
+					Lexeme:  "**=",
+					Literal: "null",
+					Line:    l.Line,
+				}, nil, nil
+			} else if err != nil && err != io.EOF {
+				return Token{}, nil, err
+			}
 			return Token{
 				Type:    TokenStarStar,
 				Lexeme:  "**",
 				Literal: "null",
 				Line:    l.Line,
 			}, nil, nil
+		case '=':
+			return Token{
+				Type:    TokenStarEqual,
+				Lexeme:  "*=",
+				Literal: "null",
+				Line:    l.Line,
+			}, nil, nil
 		default:
 			err := r.UnreadRune()
 			if err != nil {
@@ -328,107 +615,123 @@ func (l *Lexer) NextToken() (Token, TokenError, error) {
 			}, nil, nil
 		}
 	case c == '\n':
-		l.Line += 1
-		return l.NextToken()
-	case c == ' ':
-		return l.NextToken()
-	case c == '\t':
-		return l.NextToken()
-	case c == '\r':
-		return l.NextToken()
+		l.newLine()
+		l.addLeading(TriviaNewline, "\n")
+		return l.nextToken()
+	case c == ' ' || c == '\t' || c == '\r':
+		l.addLeading(TriviaWhitespace, string(c))
+		return l.nextToken()
 	case c == '"':
-		l.Lexeme.Reset()
-		l.Lexeme.WriteByte('"')
+		start := l.offset() - 1
+		decoded := strings.Builder{}
+		parts := make([]InterpolationPart, 0)
+		// where the literal text that is not yet in parts begins
+		textStart := l.offset()
+		// a bad escape is reported once the whole string has been consumed
+		var stringErr TokenError
 		for {
 			nxt, _, err := r.ReadRune()
 			if err == io.EOF {
 				// if we reach the end of the file before terminating the string with '"'
-				us := UnterminatedStringError{
-					Line: l.Line,
-				}
-				us.Message = us.FormatMessage()
-				return Token{}, us, nil
+				return Token{}, l.unterminatedString(), nil
 			}
 			if err != nil {
 				return Token{}, nil, err
 			}
-			_, err = l.Lexeme.WriteRune(nxt)
-			if err != nil {
-				return Token{}, nil, err
-			}
 			if nxt == '\n' {
-				l.Line++
+				l.newLine()
 			}
 			if nxt == '"' {
 				// we have reached the end of the string literal
-				bufBytes := l.Lexeme.Bytes()
-				lexeme := string(bufBytes)
-				return Token{
-					Type:    TokenStringLiteral,
-					Lexeme:  lexeme,
-					Literal: strings.Trim(lexeme, "\""),
-					Line:    l.Line,
-				}, nil, nil
-			}
-		}
-	case unicode.IsDigit(c):
-		hasDot := false
-		l.Lexeme.Reset()
-		_, err := l.Lexeme.WriteRune(c)
-		if err != nil {
-			return Token{}, nil, err
-		}
-		for {
-			nxt, _, err := r.ReadRune()
-			if err == io.EOF {
-				strNum := l.Lexeme.String()
-				parsedFloat, err := strconv.ParseFloat(strNum, 64)
+				if stringErr != nil {
+					return Token{}, stringErr, nil
+				}
+				lexeme, err := l.source(start)
 				if err != nil {
 					return Token{}, nil, err
 				}
-				literal := strconv.FormatFloat(parsedFloat, 'f', -1, 64)
-				if !strings.Contains(literal, ".") {
-					literal += ".0"
+				if len(parts) == 0 {
+					return Token{
+						Type:    TokenStringLiteral,
+						Lexeme:  lexeme,
+						Literal: decoded.String(),
+						Line:    l.Line,
+					}, nil, nil
+				}
+				if decoded.Len() > 0 {
+					parts = append(parts, InterpolationPart{
+						Text: decoded.String(),
+						Raw:  lexeme[textStart-start : len(lexeme)-1],
+					})
 				}
 				return Token{
-					Type:    TokenNumberLiteral,
-					Lexeme:  strNum,
-					Literal: literal,
+					Type:    TokenInterpolation,
+					Lexeme:  lexeme,
+					Literal: parts,
 					Line:    l.Line,
 				}, nil, nil
 			}
-			if err != nil {
-				return Token{}, nil, err
-			}
-			if !(unicode.IsDigit(nxt) || nxt == '.' && !hasDot) {
-				err = r.UnreadRune()
-				if err != nil {
-					return Token{}, nil, err
+			if nxt == '\\' {
+				tokErr, err := l.scanEscape(&decoded)
+				if err == io.EOF {
+					return Token{}, l.unterminatedString(), nil
 				}
-				strNum := l.Lexeme.String()
-				parsedFloat, err := strconv.ParseFloat(strNum, 64)
 				if err != nil {
 					return Token{}, nil, err
 				}
-				literal := strconv.FormatFloat(parsedFloat, 'f', -1, 64)
-				if !strings.Contains(literal, ".") {
-					literal += ".0"
+				if stringErr == nil {
+					stringErr = tokErr
 				}
-				return Token{
-					Type:    TokenNumberLiteral,
-					Lexeme:  strNum,
-					Literal: literal,
-					Line:    l.Line,
-				}, nil, nil
-			}
-			_, err = l.Lexeme.WriteRune(nxt)
-			if err != nil {
-				return Token{}, nil, err
+				continue
 			}
-			if nxt == '.' {
-				hasDot = true
+			if nxt == '$' {
+				cn, _, err := r.ReadRune()
+				if err == nil && cn == '{' {
+					// embedded expression, lexed until its closing brace
+					if decoded.Len() > 0 {
+						raw, err := l.between(textStart, l.offset()-2)
+						if err != nil {
+							return Token{}, nil, err
+						}
+						parts = append(parts, InterpolationPart{
+							Text: decoded.String(),
+							Raw:  raw,
+						})
+						decoded.Reset()
+					}
+					exprStart := l.offset()
+					toks, tokErr, err := l.scanInterpolation()
+					if err == io.EOF {
+						return Token{}, l.unterminatedString(), nil
+					}
+					if err != nil {
+						return Token{}, nil, err
+					}
+					if stringErr == nil {
+						stringErr = tokErr
+					}
+					raw, err := l.between(exprStart, l.offset()-1)
+					if err != nil {
+						return Token{}, nil, err
+					}
+					parts = append(parts, InterpolationPart{
+						Raw:    raw,
+						Tokens: toks,
+					})
+					textStart = l.offset()
+					continue
+				}
+				if err == nil {
+					err = r.UnreadRune()
+				}
+				if err != nil && err != io.EOF {
+					return Token{}, nil, err
+				}
 			}
+			decoded.WriteRune(nxt)
 		}
+	case isDecimalDigit(c):
+		return l.scanNumber(c)
 	case IsAlpha(c):
 		l.Lexeme.Reset()
 		_, err := l.Lexeme.WriteRune(c)
@@ -507,6 +810,8 @@ func (l *Lexer) ScanTokens() (TokenizedText, TokenErrors, error) {
 					Type:    TokenEOF,
 					Lexeme:  "",
 					Literal: "null",
+					Line:    l.Line,
+					Leading: l.takeLeading(),
 				}
 				toks = append(toks, eofToken)
 				return toks, tokErrs, nil
@@ -520,6 +825,276 @@ func (l *Lexer) ScanTokens() (TokenizedText, TokenErrors, error) {
 		toks = append(toks, tok)
 	}
 }
+
+// scanEscape decodes the escape sequence following a backslash inside a string
+// literal and writes the decoded rune to decoded
+func (l *Lexer) scanEscape(decoded *strings.Builder) (TokenError, error) {
+	r := l.Reader
+	// the backslash has already been read
+	column := l.column() - 1
+	invalid := func(sequence string) TokenError {
+		ie := InvalidEscapeError{
+			Sequence: sequence,
+			Line:     l.Line,
+			Column:   column,
+		}
+		ie.Message = ie.FormatMessage()
+		return ie
+	}
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return nil, err
+	}
+	switch c {
+	case '"':
+		decoded.WriteRune('"')
+	case '$':
+		decoded.WriteRune('$')
+	case '\\':
+		decoded.WriteRune('\\')
+	case 'n':
+		decoded.WriteRune('\n')
+	case 't':
+		decoded.WriteRune('\t')
+	case 'r':
+		decoded.WriteRune('\r')
+	case '0':
+		decoded.WriteRune(0)
+	case 'u':
+		sequence := "\\u"
+		open, _, err := r.ReadRune()
+		if err != nil {
+			return nil, err
+		}
+		if open != '{' {
+			if err := r.UnreadRune(); err != nil {
+				return nil, err
+			}
+			return invalid(sequence), nil
+		}
+		sequence += "{"
+		digits := make([]rune, 0, 6)
+		for {
+			d, _, err := r.ReadRune()
+			if err != nil {
+				return nil, err
+			}
+			if d == '}' {
+				sequence += "}"
+				break
+			}
+			if !isHexDigit(d) || len(digits) == 6 {
+				// leave the offending rune, it may well be the closing quote
+				if err := r.UnreadRune(); err != nil {
+					return nil, err
+				}
+				return invalid(sequence), nil
+			}
+			sequence += string(d)
+			digits = append(digits, d)
+		}
+		value, err := strconv.ParseInt(string(digits), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(value)) {
+			return invalid(sequence), nil
+		}
+		decoded.WriteRune(rune(value))
+	default:
+		if c == '\n' {
+			l.newLine()
+		}
+		return invalid("\\" + string(c)), nil
+	}
+	return nil, nil
+}
+
+// scanInterpolation lexes the expression embedded in a string after "${" up to
+// the matching "}", which is consumed but not returned
+func (l *Lexer) scanInterpolation() (TokenizedText, TokenError, error) {
+	// the trivia before the string is set aside while the embedded tokens
+	// collect their own
+	leading := l.takeLeading()
+	defer func() {
+		l.leading = leading
+	}()
+	toks := make(TokenizedText, 0)
+	var firstErr TokenError
+	depth := 0
+	for {
+		tok, tokErr, err := l.nextToken()
+		if err != nil {
+			return nil, nil, err
+		}
+		if tokErr != nil {
+			if firstErr == nil {
+				firstErr = tokErr
+			}
+			continue
+		}
+		// with Trivia enabled the embedded tokens keep their comments too
+		tok.Leading = l.takeLeading()
+		switch tok.Type {
+		case TokenLeftBrace:
+			depth++
+		case TokenRightBrace:
+			if depth == 0 {
+				// the closing brace is not a token of the expression, but
+				// what precedes it is kept on the EOF token
+				toks = append(toks, Token{
+					Type:    TokenEOF,
+					Lexeme:  "",
+					Literal: "null",
+					Line:    l.Line,
+					Leading: tok.Leading,
+				})
+				return toks, firstErr, nil
+			}
+			depth--
+		}
+		if l.Trivia {
+			// the text after the closing brace belongs to the string, so
+			// only the tokens before it can have trailing trivia
+			tok.Trailing, err = l.scanTrailing()
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		toks = append(toks, tok)
+	}
+}
+
+func (l *Lexer) unterminatedString() TokenError {
+	us := UnterminatedStringError{
+		Line: l.Line,
+	}
+	us.Message = us.FormatMessage()
+	return us
+}
+
+// source returns the text read since the start offset
+func (l *Lexer) source(start int64) (string, error) {
+	return l.between(start, l.offset())
+}
+
+// between returns the source text from the start offset up to the end offset
+func (l *Lexer) between(start, end int64) (string, error) {
+	buf := make([]byte, end-start)
+	_, err := l.Reader.ReadAt(buf, start)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// newLine moves the lexer to the next source line and remembers where that
+// line starts so that columns can be reported
+func (l *Lexer) newLine() {
+	l.Line++
+	l.lineStart = l.offset()
+}
+
+// column is the 1-based byte column of the next rune to be read
+func (l *Lexer) column() int {
+	return int(l.offset()-l.lineStart) + 1
+}
+
+func (l *Lexer) offset() int64 {
+	return l.Reader.Size() - int64(l.Reader.Len())
+}
+
+// addLeading records skipped source text so it can be attached to the next token
+func (l *Lexer) addLeading(kind TriviaKind, text string) {
+	if !l.Trivia {
+		return
+	}
+	last := len(l.leading) - 1
+	if kind == TriviaWhitespace && last >= 0 && l.leading[last].Kind == TriviaWhitespace {
+		l.leading[last].Text += text
+		return
+	}
+	l.leading = append(l.leading, Trivia{Kind: kind, Text: text})
+}
+
+func (l *Lexer) takeLeading() []Trivia {
+	leading := l.leading
+	l.leading = nil
+	return leading
+}
+
+// scanTrailing consumes the whitespace and line comment that follow a token on
+// the same line; the newline itself is left to lead the next token
+func (l *Lexer) scanTrailing() ([]Trivia, error) {
+	r := l.Reader
+	trailing := make([]Trivia, 0)
+	ws := strings.Builder{}
+	for {
+		c, size, err := r.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c == ' ' || c == '\t' || c == '\r' {
+			ws.WriteRune(c)
+			continue
+		}
+		if c == '/' {
+			cn, sizeNext, err := r.ReadRune()
+			if err == nil && cn == '/' {
+				if ws.Len() > 0 {
+					trailing = append(trailing, Trivia{Kind: TriviaWhitespace, Text: ws.String()})
+					ws.Reset()
+				}
+				comment, err := l.scanLineComment()
+				if err != nil {
+					return nil, err
+				}
+				trailing = append(trailing, Trivia{Kind: TriviaComment, Text: comment})
+				break
+			}
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if err == nil {
+				size += sizeNext
+			}
+		}
+		_, err = r.Seek(-int64(size), io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	if ws.Len() > 0 {
+		trailing = append(trailing, Trivia{Kind: TriviaWhitespace, Text: ws.String()})
+	}
+	return trailing, nil
+}
+
+// scanLineComment reads the rest of a "//" comment whose slashes were already
+// consumed, stopping before the newline
+func (l *Lexer) scanLineComment() (string, error) {
+	r := l.Reader
+	comment := []rune{'/', '/'}
+	for {
+		c, _, err := r.ReadRune()
+		if err == io.EOF {
+			return string(comment), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if c == '\n' {
+			return string(comment), r.UnreadRune()
+		}
+		comment = append(comment, c)
+	}
+}
+
+func isHexDigit(c rune) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
 func IsAlpha(c rune) bool {
 	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c == '_')
 }
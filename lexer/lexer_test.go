@@ -0,0 +1,170 @@
+package lexer
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func scan(t *testing.T, source string, trivia bool) (TokenizedText, TokenErrors) {
+	t.Helper()
+	lex := Lexer{
+		Reader: bytes.NewReader([]byte(source)),
+		Line:   1,
+		Lexeme: bytes.NewBuffer(nil),
+		Trivia: trivia,
+	}
+	toks, tokErrs, err := lex.ScanTokens()
+	if err != nil {
+		t.Fatalf("ScanTokens(%q) failed: %s", source, err)
+	}
+	return toks, tokErrs
+}
+
+func TestScanTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		tokens string
+		errors string
+	}{
+		// numbers
+		{"hexadecimal", "0x1F", "NUMBER 0x1F 31.0\nEOF  null\n", ""},
+		{"octal", "0o17", "NUMBER 0o17 15.0\nEOF  null\n", ""},
+		{"separators", "1_000", "NUMBER 1_000 1000.0\nEOF  null\n", ""},
+		{"exponent", "1e3", "NUMBER 1e3 1000.0\nEOF  null\n", ""},
+		{"fraction", "1.5", "NUMBER 1.5 1.5\nEOF  null\n", ""},
+		{"bad binary digit", "0b102", "EOF  null\n", "[line 1] Error at column 1: Malformed number literal 0b102: invalid binary digit '2'\n"},
+		{"doubled separator", "1__0", "EOF  null\n", "[line 1] Error at column 1: Malformed number literal 1__0: '_' must separate digits\n"},
+		{"trailing separator", "1_", "EOF  null\n", "[line 1] Error at column 1: Malformed number literal 1_: '_' must separate digits\n"},
+		{"missing hex digits", "0x", "EOF  null\n", "[line 1] Error at column 1: Malformed number literal 0x: expected hexadecimal digits after 0x\n"},
+		{"number out of range", "1e400", "EOF  null\n", "[line 1] Error at column 1: Malformed number literal 1e400: number out of range\n"},
+		{"missing exponent", "1e", "EOF  null\n", "[line 1] Error at column 1: Malformed number literal 1e: expected exponent digits\n"},
+		{"malformed number on a later line", "1 +\n  0x", "NUMBER 1 1.0\nPLUS + null\nEOF  null\n", "[line 2] Error at column 3: Malformed number literal 0x: expected hexadecimal digits after 0x\n"},
+		// escapes
+		{"tab escape", `"\t"`, "STRING \"\\t\" \t\nEOF  null\n", ""},
+		{"unicode escape", `"\u{41}"`, "STRING \"\\u{41}\" A\nEOF  null\n", ""},
+		{"invalid escape", `"a\q"`, "EOF  null\n", "[line 1] Error at column 3: Invalid escape sequence: \\q\n"},
+		{"unterminated string", `"abc`, "EOF  null\n", "[line 1] Error: Unterminated string."},
+		// block comments
+		{"block comment", "/* a */ 1", "NUMBER 1 1.0\nEOF  null\n", ""},
+		{"nested block comment", "/* a /* b */ c */ 1", "NUMBER 1 1.0\nEOF  null\n", ""},
+		{"block comment opener in a line comment", "// a /* b\n1", "NUMBER 1 1.0\nEOF  null\n", ""},
+		{"unterminated block comment", "/* a", "EOF  null\n", "[line 1] Error: Unterminated block comment."},
+		{"unterminated nested block comment", "/* a /* b */", "EOF  null\n", "[line 1] Error: Unterminated block comment."},
+		// interpolation
+		{"interpolation", `"x\t${ 1 + 2 }y"`, "INTERPOLATION \"x\\t${ 1 + 2 }y\" x\t${ 1 + 2 }y\nEOF  null\n", ""},
+		{"nested interpolation", `"${"${a}"}"`, "INTERPOLATION \"${\"${a}\"}\" ${\"${a}\"}\nEOF  null\n", ""},
+		{"unterminated interpolation", `"${`, "EOF  null\n", "[line 1] Error: Unterminated string."},
+		{"unterminated string after interpolation", `"${1}`, "EOF  null\n", "[line 1] Error: Unterminated string."},
+		{"dollar without brace", `"$1"`, "STRING \"$1\" $1\nEOF  null\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toks, tokErrs := scan(t, tt.source, false)
+			if got := toks.ToString(); got != tt.tokens {
+				t.Errorf("tokens of %q:\ngot  %q\nwant %q", tt.source, got, tt.tokens)
+			}
+			if got := tokErrs.ToString(); got != tt.errors {
+				t.Errorf("errors of %q:\ngot  %q\nwant %q", tt.source, got, tt.errors)
+			}
+		})
+	}
+}
+
+func TestBlockCommentLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		line   int
+	}{
+		{"single line", "/* a */ 1", 1},
+		{"multiple lines", "/* a\nb\n*/ 1", 3},
+		{"nested over lines", "/* a\n/* b\n*/\n*/\n1", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toks, tokErrs := scan(t, tt.source, false)
+			if len(tokErrs) != 0 {
+				t.Fatalf("unexpected errors: %s", tokErrs.ToString())
+			}
+			if toks[0].Line != tt.line {
+				t.Errorf("token after %q is on line %d, want %d", tt.source, toks[0].Line, tt.line)
+			}
+		})
+	}
+}
+
+func TestInterpolation(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		// the source of every part
+		raw []string
+		// the types of the tokens of every embedded expression
+		embedded [][]TokenType
+	}{
+		{
+			name:     "text around an expression",
+			source:   `"x${1 + 2}y"`,
+			raw:      []string{"x", "1 + 2", "y"},
+			embedded: [][]TokenType{{TokenNumberLiteral, TokenPlus, TokenNumberLiteral, TokenEOF}},
+		},
+		{
+			name:     "escapes are kept raw",
+			source:   `"a\n${b}"`,
+			raw:      []string{`a\n`, "b"},
+			embedded: [][]TokenType{{TokenIdentifier, TokenEOF}},
+		},
+		{
+			name:     "nested string",
+			source:   `"${"a"}"`,
+			raw:      []string{`"a"`},
+			embedded: [][]TokenType{{TokenStringLiteral, TokenEOF}},
+		},
+		{
+			name:     "closing brace inside a string",
+			source:   `"${ "}" }!"`,
+			raw:      []string{` "}" `, "!"},
+			embedded: [][]TokenType{{TokenStringLiteral, TokenEOF}},
+		},
+		{
+			name:     "braces inside the expression",
+			source:   `"${ {1: 2}[1] }!"`,
+			raw:      []string{" {1: 2}[1] ", "!"},
+			embedded: [][]TokenType{{TokenLeftBrace, TokenNumberLiteral, TokenColon, TokenNumberLiteral, TokenRightBrace, TokenLeftBracket, TokenNumberLiteral, TokenRightBracket, TokenEOF}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toks, tokErrs := scan(t, tt.source, false)
+			if len(tokErrs) != 0 {
+				t.Fatalf("unexpected errors: %s", tokErrs.ToString())
+			}
+			if toks[0].Type != TokenInterpolation {
+				t.Fatalf("%q lexed as %s, want INTERPOLATION", tt.source, toks[0].Type.ToString())
+			}
+			parts := toks[0].Literal.([]InterpolationPart)
+			if len(parts) != len(tt.raw) {
+				t.Fatalf("%q has %d parts, want %d", tt.source, len(parts), len(tt.raw))
+			}
+			embedded := 0
+			for idx, part := range parts {
+				if part.Raw != tt.raw[idx] {
+					t.Errorf("part %d of %q is %q, want %q", idx, tt.source, part.Raw, tt.raw[idx])
+				}
+				if part.Tokens == nil {
+					continue
+				}
+				types := make([]TokenType, 0, len(part.Tokens))
+				for _, tok := range part.Tokens {
+					types = append(types, tok.Type)
+				}
+				if !slices.Equal(types, tt.embedded[embedded]) {
+					t.Errorf("part %d of %q has tokens %v, want %v", idx, tt.source, types, tt.embedded[embedded])
+				}
+				embedded++
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package lexer
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// scanNumber lexes a number literal starting with the digit first: decimal
+// numbers with an optional fraction and exponent, or 0x, 0b and 0o prefixed
+// integers. Digits may be grouped with '_'.
+func (l *Lexer) scanNumber(first rune) (Token, TokenError, error) {
+	column := l.column() - 1
+	l.Lexeme.Reset()
+	l.Lexeme.WriteRune(first)
+	malformed := func(reason string) (Token, TokenError, error) {
+		mn := MalformedNumberError{
+			Lexeme: l.Lexeme.String(),
+			Reason: reason,
+			Line:   l.Line,
+			Column: column,
+		}
+		mn.Message = mn.FormatMessage()
+		return Token{}, mn, nil
+	}
+
+	if first == '0' {
+		prefix, err := l.peekRune()
+		if err != nil && err != io.EOF {
+			return Token{}, nil, err
+		}
+		if base, name := numberBase(prefix); base != 0 {
+			l.acceptRune()
+			l.Lexeme.WriteRune(prefix)
+			// take every alphanumeric rune so that bad digits are reported as part of the literal
+			digits, err := l.acceptWhile(func(c rune) bool {
+				return IsAlpha(c) || isDecimalDigit(c)
+			})
+			if err != nil {
+				return Token{}, nil, err
+			}
+			if digits == "" {
+				return malformed("expected " + name + " digits after " + l.Lexeme.String())
+			}
+			for _, c := range digits {
+				if c != '_' && !isDigitOf(c, base) {
+					return malformed("invalid " + name + " digit '" + string(c) + "'")
+				}
+			}
+			if !separatorsValid(digits, base) {
+				return malformed("'_' must separate digits")
+			}
+			value, ok := new(big.Int).SetString(strings.ReplaceAll(digits, "_", ""), base)
+			if !ok {
+				return malformed("invalid " + name + " literal")
+			}
+			floatValue, _ := new(big.Float).SetInt(value).Float64()
+			return l.numberToken(floatValue), nil, nil
+		}
+	}
+
+	if _, err := l.acceptWhile(isDecimalPart); err != nil {
+		return Token{}, nil, err
+	}
+	// a dot only belongs to the number when a digit follows, so "1.foo" stays a property access
+	dot, afterDot, err := l.peekTwoRunes()
+	if err != nil {
+		return Token{}, nil, err
+	}
+	if dot == '.' && isDecimalDigit(afterDot) {
+		l.acceptRune()
+		l.Lexeme.WriteRune('.')
+		if _, err := l.acceptWhile(isDecimalPart); err != nil {
+			return Token{}, nil, err
+		}
+	}
+	exp, err := l.peekRune()
+	if err != nil && err != io.EOF {
+		return Token{}, nil, err
+	}
+	if exp == 'e' || exp == 'E' {
+		l.acceptRune()
+		l.Lexeme.WriteRune(exp)
+		sign, err := l.peekRune()
+		if err != nil && err != io.EOF {
+			return Token{}, nil, err
+		}
+		if sign == '+' || sign == '-' {
+			l.acceptRune()
+			l.Lexeme.WriteRune(sign)
+		}
+		digits, err := l.acceptWhile(isDecimalPart)
+		if err != nil {
+			return Token{}, nil, err
+		}
+		if digits == "" || !isDecimalDigit(rune(digits[0])) {
+			return malformed("expected exponent digits")
+		}
+	}
+	if !separatorsValid(l.Lexeme.String(), 10) {
+		return malformed("'_' must separate digits")
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(l.Lexeme.String(), "_", ""), 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return malformed("number out of range")
+	}
+	if err != nil {
+		return malformed("invalid number literal")
+	}
+	return l.numberToken(value), nil, nil
+}
+
+// ParseInteger returns the exact value of a number lexeme that has neither a
+// fraction nor an exponent
+func ParseInteger(lexeme string) (*big.Int, bool) {
+	digits := strings.ReplaceAll(lexeme, "_", "")
+	base := 10
+	if len(digits) > 2 && digits[0] == '0' {
+		if prefixBase, _ := numberBase(rune(digits[1])); prefixBase != 0 {
+			base = prefixBase
+			digits = digits[2:]
+		}
+	}
+	if base == 10 && strings.ContainsAny(digits, ".eE") {
+		return nil, false
+	}
+	return new(big.Int).SetString(digits, base)
+}
+
+func (l *Lexer) numberToken(value float64) Token {
+	literal := strconv.FormatFloat(value, 'f', -1, 64)
+	if !strings.Contains(literal, ".") {
+		literal += ".0"
+	}
+	return Token{
+		Type:    TokenNumberLiteral,
+		Lexeme:  l.Lexeme.String(),
+		Literal: literal,
+		Line:    l.Line,
+	}
+}
+
+// acceptWhile consumes the runes matching pred, writing them to the lexeme,
+// and returns them
+func (l *Lexer) acceptWhile(pred func(rune) bool) (string, error) {
+	sb := strings.Builder{}
+	for {
+		c, err := l.peekRune()
+		if err == io.EOF {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if !pred(c) {
+			return sb.String(), nil
+		}
+		l.acceptRune()
+		l.Lexeme.WriteRune(c)
+		sb.WriteRune(c)
+	}
+}
+
+// peekRune returns the next rune without consuming it
+func (l *Lexer) peekRune() (rune, error) {
+	c, _, err := l.Reader.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	return c, l.Reader.UnreadRune()
+}
+
+// peekTwoRunes returns the next two runes without consuming them, 0 standing in
+// for the end of the input
+func (l *Lexer) peekTwoRunes() (rune, rune, error) {
+	start := l.offset()
+	runes := [2]rune{}
+	for i := range runes {
+		c, _, err := l.Reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		runes[i] = c
+	}
+	_, err := l.Reader.Seek(start, io.SeekStart)
+	return runes[0], runes[1], err
+}
+
+// acceptRune consumes a rune that was just peeked
+func (l *Lexer) acceptRune() {
+	l.Reader.ReadRune()
+}
+
+func numberBase(prefix rune) (int, string) {
+	switch prefix {
+	case 'x', 'X':
+		return 16, "hexadecimal"
+	case 'b', 'B':
+		return 2, "binary"
+	case 'o', 'O':
+		return 8, "octal"
+	}
+	return 0, ""
+}
+
+// separatorsValid reports whether every '_' in text sits between two digits
+func separatorsValid(text string, base int) bool {
+	for i, c := range text {
+		if c != '_' {
+			continue
+		}
+		if i == 0 || i == len(text)-1 || !isDigitOf(rune(text[i-1]), base) || !isDigitOf(rune(text[i+1]), base) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigitOf(c rune, base int) bool {
+	switch base {
+	case 2:
+		return c == '0' || c == '1'
+	case 8:
+		return c >= '0' && c <= '7'
+	case 16:
+		return isHexDigit(c)
+	}
+	return isDecimalDigit(c)
+}
+
+func isDecimalDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isDecimalPart(c rune) bool {
+	return isDecimalDigit(c) || c == '_'
+}
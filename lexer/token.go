@@ -1,5 +1,7 @@
 package lexer
 
+import "strings"
+
 type TokenType int
 
 const (
@@ -9,6 +11,8 @@ const (
 	TokenRightParen
 	TokenLeftBrace
 	TokenRightBrace
+	TokenLeftBracket
+	TokenRightBracket
 	// punctuation
 	TokenComma
 	TokenDot
@@ -24,25 +28,52 @@ const (
 	TokenGreater
 	TokenGreaterEqual
 	TokenStarStar
+	TokenPercent
+	TokenTildeSlash
+	// assignment operators
+	TokenPlusEqual
+	TokenMinusEqual
+	TokenStarEqual
+	TokenSlashEqual
+	TokenStarStarEqual
+	TokenPlusPlus
+	TokenMinusMinus
+	// bitwise operators
+	TokenAmpersand
+	TokenPipe
+	TokenCaret
+	TokenTilde
+	TokenLessLess
+	TokenGreaterGreater
 	// ternary operator symbols
 	TokenColon
 	TokenQuestionMark
+	// nil-safe operators
+	TokenQuestionQuestion
+	TokenQuestionDot
 	// misc
 	TokenSlash
 	TokenStar
 	TokenBang
 	TokenIdentifier
+	TokenArrow
+	TokenEllipsis
 	// literals
 	TokenStringLiteral
 	TokenNumberLiteral
+	TokenInterpolation
 	// keywords
 	TokenAnd
+	TokenBreak
+	TokenCase
 	TokenClass
+	TokenContinue
 	TokenElse
 	TokenFalse
 	TokenFor
 	TokenFun
 	TokenIf
+	TokenMatch
 	TokenNil
 	TokenOr
 	TokenPrint
@@ -66,6 +97,10 @@ func (t TokenType) ToString() string {
 		return "LEFT_BRACE"
 	case TokenRightBrace:
 		return "RIGHT_BRACE"
+	case TokenLeftBracket:
+		return "LEFT_BRACKET"
+	case TokenRightBracket:
+		return "RIGHT_BRACKET"
 	case TokenComma:
 		return "COMMA"
 	case TokenDot:
@@ -98,6 +133,36 @@ func (t TokenType) ToString() string {
 		return "GREATER_EQUAL"
 	case TokenStarStar:
 		return "DOUBLE_STAR"
+	case TokenPercent:
+		return "PERCENT"
+	case TokenTildeSlash:
+		return "TILDE_SLASH"
+	case TokenPlusEqual:
+		return "PLUS_EQUAL"
+	case TokenMinusEqual:
+		return "MINUS_EQUAL"
+	case TokenStarEqual:
+		return "STAR_EQUAL"
+	case TokenSlashEqual:
+		return "SLASH_EQUAL"
+	case TokenStarStarEqual:
+		return "DOUBLE_STAR_EQUAL"
+	case TokenPlusPlus:
+		return "PLUS_PLUS"
+	case TokenMinusMinus:
+		return "MINUS_MINUS"
+	case TokenAmpersand:
+		return "AMPERSAND"
+	case TokenPipe:
+		return "PIPE"
+	case TokenCaret:
+		return "CARET"
+	case TokenTilde:
+		return "TILDE"
+	case TokenLessLess:
+		return "LESS_LESS"
+	case TokenGreaterGreater:
+		return "GREATER_GREATER"
 	case TokenColon:
 		return "COLON"
 	case TokenQuestionMark:
@@ -106,10 +171,18 @@ func (t TokenType) ToString() string {
 		return "STRING"
 	case TokenNumberLiteral:
 		return "NUMBER"
+	case TokenInterpolation:
+		return "INTERPOLATION"
 	case TokenAnd:
 		return "AND"
+	case TokenBreak:
+		return "BREAK"
+	case TokenCase:
+		return "CASE"
 	case TokenClass:
 		return "CLASS"
+	case TokenContinue:
+		return "CONTINUE"
 	case TokenElse:
 		return "ELSE"
 	case TokenFalse:
@@ -120,6 +193,8 @@ func (t TokenType) ToString() string {
 		return "FUN"
 	case TokenIf:
 		return "IF"
+	case TokenMatch:
+		return "MATCH"
 	case TokenNil:
 		return "NIL"
 	case TokenOr:
@@ -138,24 +213,70 @@ func (t TokenType) ToString() string {
 		return "VAR"
 	case TokenWhile:
 		return "WHILE"
+	case TokenQuestionQuestion:
+		return "QUESTION_QUESTION"
+	case TokenQuestionDot:
+		return "QUESTION_DOT"
 	case TokenIdentifier:
 		return "IDENTIFIER"
+	case TokenArrow:
+		return "ARROW"
+	case TokenEllipsis:
+		return "ELLIPSIS"
 	default:
 		return ""
 	}
 }
 
+type TriviaKind int
+
+const (
+	TriviaWhitespace TriviaKind = iota
+	TriviaNewline
+	TriviaComment
+)
+
+// Trivia is source text that carries no meaning for the parser
+type Trivia struct {
+	Kind TriviaKind
+	Text string
+}
+
+// InterpolationPart is one segment of an interpolated string, either literal
+// text or the tokens of an embedded expression (terminated by an EOF token).
+// Raw is the source of the segment as written, escape sequences included and
+// without the "${" and "}" around an expression.
+type InterpolationPart struct {
+	Text   string
+	Raw    string
+	Tokens TokenizedText
+}
+
 type Token struct {
 	Type    TokenType
 	Lexeme  string
 	Literal interface{}
 	Line    int
+	// only filled in when the Lexer runs with Trivia enabled
+	Leading  []Trivia
+	Trailing []Trivia
 }
 
 func LiteralToString(lit interface{}) string {
 	switch v := lit.(type) {
 	case string:
 		return v
+	case []InterpolationPart:
+		// the decoded text with the embedded expressions as written
+		sb := strings.Builder{}
+		for _, part := range v {
+			if part.Tokens == nil {
+				sb.WriteString(part.Text)
+			} else {
+				sb.WriteString("${" + part.Raw + "}")
+			}
+		}
+		return sb.String()
 	default:
 		return ""
 	}
@@ -26,6 +26,7 @@ func TransformToStringAST(expr exprVisitors.Expr[any, interface{}]) exprVisitors
 		return &exprVisitors.Literal[any, string]{
 			Value: fmt.Sprintf("%v", e.Value),
 			Type:  e.Type,
+			Token: e.Token,
 		}
 	case *exprVisitors.Comma[any, interface{}]:
 		return &exprVisitors.Comma[any, string]{
@@ -38,6 +39,172 @@ func TransformToStringAST(expr exprVisitors.Expr[any, interface{}]) exprVisitors
 			Middle: TransformToStringAST(e.Middle),
 			Right:  TransformToStringAST(e.Right),
 		}
+	case *exprVisitors.Interpolation[any, interface{}]:
+		return &exprVisitors.Interpolation[any, string]{
+			Token: e.Token,
+			Parts: transformAll(e.Parts),
+		}
+	case *exprVisitors.List[any, interface{}]:
+		return &exprVisitors.List[any, string]{
+			Bracket:  e.Bracket,
+			Elements: transformAll(e.Elements),
+		}
+	case *exprVisitors.Index[any, interface{}]:
+		return transformIndex(e)
+	case *exprVisitors.Slice[any, interface{}]:
+		slice := &exprVisitors.Slice[any, string]{
+			Object:  TransformToStringAST(e.Object),
+			Bracket: e.Bracket,
+		}
+		if e.Start != nil {
+			slice.Start = TransformToStringAST(e.Start)
+		}
+		if e.End != nil {
+			slice.End = TransformToStringAST(e.End)
+		}
+		return slice
+	case *exprVisitors.SetIndex[any, interface{}]:
+		setIndex := &exprVisitors.SetIndex[any, string]{
+			Target:   transformIndex(e.Target),
+			Operator: e.Operator,
+			Prefix:   e.Prefix,
+		}
+		if e.Value != nil {
+			setIndex.Value = TransformToStringAST(e.Value)
+		}
+		return setIndex
+	case *exprVisitors.Get[any, interface{}]:
+		return &exprVisitors.Get[any, string]{
+			Object:   TransformToStringAST(e.Object),
+			Name:     e.Name,
+			Optional: e.Optional,
+		}
+	case *exprVisitors.OptionalChain[any, interface{}]:
+		return &exprVisitors.OptionalChain[any, string]{
+			Expression: TransformToStringAST(e.Expression),
+		}
+	case *exprVisitors.Call[any, interface{}]:
+		return &exprVisitors.Call[any, string]{
+			Callee:    TransformToStringAST(e.Callee),
+			Paren:     e.Paren,
+			Arguments: transformAll(e.Arguments),
+		}
+	case *exprVisitors.Map[any, interface{}]:
+		return &exprVisitors.Map[any, string]{
+			Brace:  e.Brace,
+			Keys:   transformAll(e.Keys),
+			Values: transformAll(e.Values),
+		}
+	case *exprVisitors.Variable[any, interface{}]:
+		return &exprVisitors.Variable[any, string]{
+			Name: e.Name,
+		}
+	case *exprVisitors.Lambda[any, interface{}]:
+		lambda := &exprVisitors.Lambda[any, string]{
+			Paren:    e.Paren,
+			Params:   e.Params,
+			Defaults: make([]exprVisitors.Expr[any, string], len(e.Defaults)),
+			Patterns: make([]exprVisitors.Pattern[any, string], len(e.Patterns)),
+			Rest:     e.Rest,
+			Arrow:    e.Arrow,
+			Body:     TransformToStringAST(e.Body),
+		}
+		for idx, def := range e.Defaults {
+			if def != nil {
+				lambda.Defaults[idx] = TransformToStringAST(def)
+			}
+		}
+		for idx, pattern := range e.Patterns {
+			if pattern != nil {
+				lambda.Patterns[idx] = transformPattern(pattern)
+			}
+		}
+		return lambda
+	case *exprVisitors.Match[any, interface{}]:
+		match := &exprVisitors.Match[any, string]{
+			Keyword: e.Keyword,
+			Subject: TransformToStringAST(e.Subject),
+		}
+		for _, arm := range e.Arms {
+			transformed := &exprVisitors.MatchArm[any, string]{
+				Case:    arm.Case,
+				Pattern: transformPattern(arm.Pattern),
+				Body:    TransformToStringAST(arm.Body),
+			}
+			if arm.Guard != nil {
+				transformed.Guard = TransformToStringAST(arm.Guard)
+			}
+			match.Arms = append(match.Arms, transformed)
+		}
+		return match
+	case *exprVisitors.Spread[any, interface{}]:
+		return &exprVisitors.Spread[any, string]{
+			Ellipsis:   e.Ellipsis,
+			Expression: TransformToStringAST(e.Expression),
+		}
 	}
 	panic("unknown expr type")
 }
+
+func transformAll(exprs []exprVisitors.Expr[any, interface{}]) []exprVisitors.Expr[any, string] {
+	out := make([]exprVisitors.Expr[any, string], 0, len(exprs))
+	for _, expr := range exprs {
+		out = append(out, TransformToStringAST(expr))
+	}
+	return out
+}
+
+func transformPattern(p exprVisitors.Pattern[any, interface{}]) exprVisitors.Pattern[any, string] {
+	switch pt := p.(type) {
+	case *exprVisitors.LiteralPattern[any, interface{}]:
+		return &exprVisitors.LiteralPattern[any, string]{
+			Value: TransformToStringAST(pt.Value),
+		}
+	case *exprVisitors.WildcardPattern[any, interface{}]:
+		return &exprVisitors.WildcardPattern[any, string]{
+			Token: pt.Token,
+		}
+	case *exprVisitors.BindingPattern[any, interface{}]:
+		return &exprVisitors.BindingPattern[any, string]{
+			Name: pt.Name,
+		}
+	case *exprVisitors.ListPattern[any, interface{}]:
+		list := &exprVisitors.ListPattern[any, string]{
+			Bracket: pt.Bracket,
+			Rest:    pt.Rest,
+		}
+		for _, element := range pt.Elements {
+			list.Elements = append(list.Elements, transformPattern(element))
+		}
+		return list
+	case *exprVisitors.MapPattern[any, interface{}]:
+		m := &exprVisitors.MapPattern[any, string]{
+			Brace: pt.Brace,
+			Rest:  pt.Rest,
+		}
+		for idx, key := range pt.Keys {
+			// shorthand entries have no key
+			if key == nil {
+				m.Keys = append(m.Keys, nil)
+			} else {
+				m.Keys = append(m.Keys, TransformToStringAST(key))
+			}
+			m.Values = append(m.Values, transformPattern(pt.Values[idx]))
+		}
+		return m
+	case *exprVisitors.DefaultPattern[any, interface{}]:
+		return &exprVisitors.DefaultPattern[any, string]{
+			Pattern: transformPattern(pt.Pattern),
+			Default: TransformToStringAST(pt.Default),
+		}
+	}
+	panic("unknown pattern type")
+}
+
+func transformIndex(e *exprVisitors.Index[any, interface{}]) *exprVisitors.Index[any, string] {
+	return &exprVisitors.Index[any, string]{
+		Object:  TransformToStringAST(e.Object),
+		Bracket: e.Bracket,
+		Index:   TransformToStringAST(e.Index),
+	}
+}
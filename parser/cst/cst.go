@@ -0,0 +1,336 @@
+package cst
+
+import (
+	"fmt"
+	"strings"
+
+	"github/goInterpreter/lexer"
+	"github/goInterpreter/parser/exprVisitors"
+)
+
+// Node is a concrete syntax tree node. Inner nodes wrap an expression of the
+// AST and list its sub-expressions and its own tokens in source order; leaves
+// hold a single token together with its trivia.
+type Node struct {
+	Kind     string
+	Expr     exprVisitors.Expr[any, interface{}]
+	Token    *lexer.Token
+	Children []*Node
+	// the trees of the expressions embedded in an interpolated string. Their
+	// source is part of the string token, so they are not written out again.
+	Embedded []*Node
+}
+
+// Build lays the tokens of a successfully parsed expression over its AST. The
+// tokens should come from a Lexer with Trivia enabled for the tree to be lossless.
+func Build(expr exprVisitors.Expr[any, interface{}], tokens lexer.TokenizedText) (*Node, error) {
+	b := builder{
+		tokens: tokens,
+	}
+	root := &Node{
+		Kind: "File",
+	}
+	if expr != nil {
+		child, err := b.expr(expr)
+		if err != nil {
+			return nil, err
+		}
+		root.Children = append(root.Children, child)
+	}
+	eof, err := b.token(root, lexer.TokenEOF)
+	if err != nil {
+		return nil, err
+	}
+	root.Children = append(root.Children, eof)
+	return root, nil
+}
+
+// String reproduces the source text the node was built from
+func (n *Node) String() string {
+	sb := strings.Builder{}
+	n.write(&sb)
+	return sb.String()
+}
+
+func (n *Node) write(sb *strings.Builder) {
+	if n.Token != nil {
+		for _, tr := range n.Token.Leading {
+			sb.WriteString(tr.Text)
+		}
+		sb.WriteString(n.Token.Lexeme)
+		for _, tr := range n.Token.Trailing {
+			sb.WriteString(tr.Text)
+		}
+		return
+	}
+	for _, child := range n.Children {
+		child.write(sb)
+	}
+}
+
+// Tokens returns the tokens of every node in the tree keyed by the expression
+// that owns them, leaving out the tokens of its sub-expressions
+func (n *Node) Tokens() map[exprVisitors.Expr[any, interface{}]][]lexer.Token {
+	owned := make(map[exprVisitors.Expr[any, interface{}]][]lexer.Token)
+	n.collect(owned)
+	return owned
+}
+
+func (n *Node) collect(owned map[exprVisitors.Expr[any, interface{}]][]lexer.Token) {
+	for _, child := range n.Children {
+		if child.Token != nil && n.Expr != nil {
+			owned[n.Expr] = append(owned[n.Expr], *child.Token)
+			continue
+		}
+		child.collect(owned)
+	}
+	for _, tree := range n.Embedded {
+		tree.collect(owned)
+	}
+}
+
+type builder struct {
+	tokens   lexer.TokenizedText
+	position int
+}
+
+func (b *builder) expr(expr exprVisitors.Expr[any, interface{}]) (*Node, error) {
+	switch e := expr.(type) {
+	case *exprVisitors.Binary[any, interface{}]:
+		return b.node("Binary", e, e.Left, e.Operator.Type, e.Right)
+	case *exprVisitors.Unary[any, interface{}]:
+		return b.node("Unary", e, e.Operator.Type, e.Right)
+	case *exprVisitors.Grouping[any, interface{}]:
+		return b.node("Grouping", e, lexer.TokenLeftParen, e.Expression, lexer.TokenRightParen)
+	case *exprVisitors.Literal[any, interface{}]:
+		return b.node("Literal", e, e.Token.Type)
+	case *exprVisitors.Comma[any, interface{}]:
+		return b.node("Comma", e, e.Left, lexer.TokenComma, e.Right)
+	case *exprVisitors.Ternary[any, interface{}]:
+		return b.node("Ternary", e, e.Left, lexer.TokenQuestionMark, e.Middle, lexer.TokenColon, e.Right)
+	case *exprVisitors.Interpolation[any, interface{}]:
+		// the embedded expressions live inside the string token
+		n, err := b.node("Interpolation", e, lexer.TokenInterpolation)
+		if err != nil {
+			return nil, err
+		}
+		n.Embedded, err = embedded(e)
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	case *exprVisitors.List[any, interface{}]:
+		return b.node("List", e, delimited(lexer.TokenLeftBracket, e.Elements, lexer.TokenRightBracket)...)
+	case *exprVisitors.Index[any, interface{}]:
+		return b.node("Index", e, e.Object, lexer.TokenLeftBracket, e.Index, lexer.TokenRightBracket)
+	case *exprVisitors.Slice[any, interface{}]:
+		parts := []interface{}{e.Object, lexer.TokenLeftBracket}
+		if e.Start != nil {
+			parts = append(parts, e.Start)
+		}
+		parts = append(parts, lexer.TokenColon)
+		if e.End != nil {
+			parts = append(parts, e.End)
+		}
+		return b.node("Slice", e, append(parts, lexer.TokenRightBracket)...)
+	case *exprVisitors.SetIndex[any, interface{}]:
+		switch {
+		case e.Value != nil:
+			return b.node("SetIndex", e, e.Target, e.Operator.Type, e.Value)
+		case e.Prefix:
+			return b.node("SetIndex", e, e.Operator.Type, e.Target)
+		}
+		return b.node("SetIndex", e, e.Target, e.Operator.Type)
+	case *exprVisitors.Get[any, interface{}]:
+		if e.Optional {
+			return b.node("Get", e, e.Object, lexer.TokenQuestionDot, lexer.TokenIdentifier)
+		}
+		return b.node("Get", e, e.Object, lexer.TokenDot, lexer.TokenIdentifier)
+	case *exprVisitors.OptionalChain[any, interface{}]:
+		return b.node("OptionalChain", e, e.Expression)
+	case *exprVisitors.Call[any, interface{}]:
+		parts := delimited(lexer.TokenLeftParen, e.Arguments, lexer.TokenRightParen)
+		return b.node("Call", e, append([]interface{}{e.Callee}, parts...)...)
+	case *exprVisitors.Map[any, interface{}]:
+		parts := []interface{}{lexer.TokenLeftBrace}
+		for idx := range e.Keys {
+			if idx > 0 {
+				parts = append(parts, lexer.TokenComma)
+			}
+			parts = append(parts, e.Keys[idx], lexer.TokenColon, e.Values[idx])
+		}
+		if len(e.Keys) > 0 {
+			parts = append(parts, optional(lexer.TokenComma))
+		}
+		return b.node("Map", e, append(parts, lexer.TokenRightBrace)...)
+	case *exprVisitors.Variable[any, interface{}]:
+		return b.node("Variable", e, lexer.TokenIdentifier)
+	case *exprVisitors.Lambda[any, interface{}]:
+		parts := []interface{}{lexer.TokenLeftParen}
+		for idx := range e.Params {
+			if idx > 0 {
+				parts = append(parts, lexer.TokenComma)
+			}
+			if e.Rest && idx == len(e.Params)-1 {
+				parts = append(parts, lexer.TokenEllipsis)
+			}
+			if e.Patterns[idx] != nil {
+				parts = append(parts, patternParts(e.Patterns[idx])...)
+			} else {
+				parts = append(parts, lexer.TokenIdentifier)
+			}
+			if e.Defaults[idx] != nil {
+				parts = append(parts, lexer.TokenEqual, e.Defaults[idx])
+			}
+		}
+		if len(e.Params) > 0 {
+			parts = append(parts, optional(lexer.TokenComma))
+		}
+		return b.node("Lambda", e, append(parts, lexer.TokenRightParen, lexer.TokenArrow, e.Body)...)
+	case *exprVisitors.Match[any, interface{}]:
+		parts := []interface{}{lexer.TokenMatch, e.Subject, lexer.TokenLeftBrace}
+		for _, arm := range e.Arms {
+			parts = append(parts, lexer.TokenCase)
+			parts = append(parts, patternParts(arm.Pattern)...)
+			if arm.Guard != nil {
+				parts = append(parts, lexer.TokenIf, arm.Guard)
+			}
+			parts = append(parts, lexer.TokenArrow, arm.Body)
+		}
+		return b.node("Match", e, append(parts, lexer.TokenRightBrace)...)
+	case *exprVisitors.Spread[any, interface{}]:
+		return b.node("Spread", e, lexer.TokenEllipsis, e.Expression)
+	}
+	return nil, fmt.Errorf("cst: unknown expression %T", expr)
+}
+
+// patternParts lists the parts of a match pattern. Patterns are not
+// expressions, so their tokens are owned by the enclosing match.
+func patternParts(p exprVisitors.Pattern[any, interface{}]) []interface{} {
+	switch pt := p.(type) {
+	case *exprVisitors.LiteralPattern[any, interface{}]:
+		return []interface{}{pt.Value}
+	case *exprVisitors.WildcardPattern[any, interface{}], *exprVisitors.BindingPattern[any, interface{}]:
+		return []interface{}{lexer.TokenIdentifier}
+	case *exprVisitors.ListPattern[any, interface{}]:
+		parts := []interface{}{lexer.TokenLeftBracket}
+		for idx, element := range pt.Elements {
+			if idx > 0 {
+				parts = append(parts, lexer.TokenComma)
+			}
+			parts = append(parts, patternParts(element)...)
+		}
+		if pt.Rest != nil {
+			if len(pt.Elements) > 0 {
+				parts = append(parts, lexer.TokenComma)
+			}
+			parts = append(parts, lexer.TokenEllipsis, lexer.TokenIdentifier)
+		}
+		return append(parts, lexer.TokenRightBracket)
+	case *exprVisitors.MapPattern[any, interface{}]:
+		parts := []interface{}{lexer.TokenLeftBrace}
+		for idx := range pt.Keys {
+			if idx > 0 {
+				parts = append(parts, lexer.TokenComma)
+			}
+			if pt.Keys[idx] != nil {
+				parts = append(parts, pt.Keys[idx], lexer.TokenColon)
+			}
+			parts = append(parts, patternParts(pt.Values[idx])...)
+		}
+		if pt.Rest != nil {
+			if len(pt.Keys) > 0 {
+				parts = append(parts, lexer.TokenComma)
+			}
+			parts = append(parts, lexer.TokenEllipsis, lexer.TokenIdentifier)
+		}
+		return append(parts, lexer.TokenRightBrace)
+	case *exprVisitors.DefaultPattern[any, interface{}]:
+		return append(patternParts(pt.Pattern), lexer.TokenEqual, pt.Default)
+	}
+	return []interface{}{nil}
+}
+
+// embedded builds the trees of the expressions embedded in an interpolated
+// string out of their own tokens. The EOF token that ends each of them holds
+// the trivia before its closing brace and is owned by the interpolation.
+func embedded(in *exprVisitors.Interpolation[any, interface{}]) ([]*Node, error) {
+	trees := make([]*Node, 0)
+	for idx, part := range in.Token.Literal.([]lexer.InterpolationPart) {
+		if part.Tokens == nil {
+			continue
+		}
+		b := builder{
+			tokens: part.Tokens,
+		}
+		tree, err := b.node("Embedded", in, in.Parts[idx], lexer.TokenEOF)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree)
+	}
+	return trees, nil
+}
+
+// optional is the type of a token that a node owns only if it is present
+type optional lexer.TokenType
+
+// delimited lists the parts of a bracketed, comma separated list of
+// expressions that may end with a trailing comma
+func delimited(open lexer.TokenType, elements []exprVisitors.Expr[any, interface{}], close lexer.TokenType) []interface{} {
+	parts := []interface{}{open}
+	for idx, element := range elements {
+		if idx > 0 {
+			parts = append(parts, lexer.TokenComma)
+		}
+		parts = append(parts, element)
+	}
+	if len(elements) > 0 {
+		parts = append(parts, optional(lexer.TokenComma))
+	}
+	return append(parts, close)
+}
+
+// node builds the node for expr out of its parts in source order, each part
+// being either a sub-expression, the type of a token the node owns or an
+// optional token
+func (b *builder) node(kind string, expr exprVisitors.Expr[any, interface{}], parts ...interface{}) (*Node, error) {
+	n := &Node{
+		Kind: kind,
+		Expr: expr,
+	}
+	for _, part := range parts {
+		var child *Node
+		var err error
+		switch p := part.(type) {
+		case lexer.TokenType:
+			child, err = b.token(n, p)
+		case optional:
+			if b.position >= len(b.tokens) || b.tokens[b.position].Type != lexer.TokenType(p) {
+				continue
+			}
+			child, err = b.token(n, lexer.TokenType(p))
+		case exprVisitors.Expr[any, interface{}]:
+			child, err = b.expr(p)
+		default:
+			err = fmt.Errorf("cst: incomplete %s expression", kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, child)
+	}
+	return n, nil
+}
+
+func (b *builder) token(parent *Node, tokType lexer.TokenType) (*Node, error) {
+	if b.position >= len(b.tokens) || b.tokens[b.position].Type != tokType {
+		return nil, fmt.Errorf("cst: expected %s token in %s", tokType.ToString(), parent.Kind)
+	}
+	tok := &b.tokens[b.position]
+	b.position++
+	return &Node{
+		Kind:  tokType.ToString(),
+		Token: tok,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package cst
+
+import (
+	"bytes"
+	"testing"
+
+	"github/goInterpreter/lexer"
+	"github/goInterpreter/parser"
+)
+
+func TestBuildRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"binary", "1 + 2 * 3"},
+		{"unary and grouping", "-(1 + 2)"},
+		{"ternary and comma", "1 ? 2 : 3, 4"},
+		{"coalesce and optional chain", "a?.b.c() ?? d?.e[0]"},
+		{"line comments", "1 // one\n+ // plus\n2 // two\n"},
+		{"block comments", "/* a */ 1 /* b */ + /* c /* nested */ */ 2"},
+		{"comment at the end of the file", "1\n// the end\n"},
+		{"blank lines and tabs", "\n\t1\n\n+\t2\n\n"},
+		{"string escapes", `"a\tb\u{41}" + "\""`},
+		{"interpolation", `"x${ 1 + /* c */ 2 }y${"z"}"`},
+		{"list with trailing comma", "[1, 2, 3,]"},
+		{"map with trailing comma", "{1: 2, \"a\": [3],}"},
+		{"index, slice and assignment", "[1][1:][0] += [2][:-1][0]++"},
+		{"lambda", "(a, b) => a + b"},
+		{"calls with spread", "((...r) => r)(1, ...[2],)"},
+		{"default and rest parameters", "(a, b = 1, ...rest) => a + b"},
+		{"destructured parameters", "([x, y = 2], {a, \"b\": c, ...r},) => x"},
+		{"match", "match x {\n  case 1 => \"one\" // one\n  case [a, ...r] if (a) => r\n  case {\"k\": v, w} => v\n  case _ => nil\n}"},
+		{"nested match in a string", `"${match x { case _ => 1 }}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lex := lexer.Lexer{
+				Reader: bytes.NewReader([]byte(tt.source)),
+				Line:   1,
+				Lexeme: bytes.NewBuffer(nil),
+				Trivia: true,
+			}
+			toks, tokErrs, err := lex.ScanTokens()
+			if err != nil || len(tokErrs) != 0 {
+				t.Fatalf("lexing %q failed: %v %s", tt.source, err, tokErrs.ToString())
+			}
+			p := parser.Parser{
+				Tokens: toks,
+			}
+			expr := p.Parse()
+			if p.HadError {
+				t.Fatalf("parsing %q failed", tt.source)
+			}
+			tree, err := Build(expr, toks)
+			if err != nil {
+				t.Fatalf("Build(%q) failed: %s", tt.source, err)
+			}
+			if got := tree.String(); got != tt.source {
+				t.Errorf("Build(%q).String() = %q", tt.source, got)
+			}
+		})
+	}
+}
@@ -21,3 +21,8 @@ func (pe ParserError) Report(token lexer.Token) string {
 	}
 	return errorMsg
 }
+
+// Warn formats a problem that does not stop the expression from running
+func (pe ParserError) Warn(token lexer.Token) string {
+	return fmt.Sprintf("[line %s] Warning at %s: %s", strconv.Itoa(pe.Line), token.Lexeme, pe.Message)
+}
@@ -2,6 +2,7 @@ package exprVisitors
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github/goInterpreter/lexer"
@@ -18,6 +19,19 @@ type ExprVisitor[T, V any] interface {
 	VisitLiteral(*Literal[T, V]) V
 	VisitComma(*Comma[T, V]) V
 	VisitTernary(*Ternary[T, V]) V
+	VisitInterpolation(*Interpolation[T, V]) V
+	VisitList(*List[T, V]) V
+	VisitIndex(*Index[T, V]) V
+	VisitSlice(*Slice[T, V]) V
+	VisitSetIndex(*SetIndex[T, V]) V
+	VisitGet(*Get[T, V]) V
+	VisitCall(*Call[T, V]) V
+	VisitMap(*Map[T, V]) V
+	VisitVariable(*Variable[T, V]) V
+	VisitLambda(*Lambda[T, V]) V
+	VisitSpread(*Spread[T, V]) V
+	VisitMatch(*Match[T, V]) V
+	VisitOptionalChain(*OptionalChain[T, V]) V
 }
 
 type Expr[T, V any] interface {
@@ -56,6 +70,7 @@ func (gr *Grouping[T, V]) Accept(visitor ExprVisitor[T, V]) V {
 type Literal[T, V any] struct {
 	Value T
 	Type  string
+	Token lexer.Token
 }
 
 func (l *Literal[T, V]) Accept(visitor ExprVisitor[T, V]) V {
@@ -81,6 +96,158 @@ func (t *Ternary[T, V]) Accept(visitor ExprVisitor[T, V]) V {
 	return visitor.VisitTernary(t)
 }
 
+type Interpolation[T, V any] struct {
+	// the whole string literal, kept for its source text
+	Token lexer.Token
+	Parts []Expr[T, V]
+}
+
+func (in *Interpolation[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitInterpolation(in)
+}
+
+type List[T, V any] struct {
+	Bracket  lexer.Token
+	Elements []Expr[T, V]
+}
+
+func (li *List[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitList(li)
+}
+
+type Index[T, V any] struct {
+	Object  Expr[T, V]
+	Bracket lexer.Token
+	Index   Expr[T, V]
+}
+
+func (ix *Index[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitIndex(ix)
+}
+
+// Slice takes the elements from Start up to End, either of which may be nil
+type Slice[T, V any] struct {
+	Object  Expr[T, V]
+	Bracket lexer.Token
+	Start   Expr[T, V]
+	End     Expr[T, V]
+}
+
+func (sl *Slice[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitSlice(sl)
+}
+
+// SetIndex assigns to Target. Operator is "=", a compound assignment operator
+// or, with a nil Value, a prefix or postfix "++" or "--".
+type SetIndex[T, V any] struct {
+	Target   *Index[T, V]
+	Operator lexer.Token
+	Value    Expr[T, V]
+	Prefix   bool
+}
+
+func (si *SetIndex[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitSetIndex(si)
+}
+
+// Get reads the property Name of Object. An Optional get ("?.") of nil ends
+// the enclosing OptionalChain.
+type Get[T, V any] struct {
+	Object   Expr[T, V]
+	Name     lexer.Token
+	Optional bool
+}
+
+func (g *Get[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitGet(g)
+}
+
+type Call[T, V any] struct {
+	Callee    Expr[T, V]
+	Paren     lexer.Token
+	Arguments []Expr[T, V]
+}
+
+func (c *Call[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitCall(c)
+}
+
+// Map is a map literal whose i-th entry is Keys[i]: Values[i]
+type Map[T, V any] struct {
+	Brace  lexer.Token
+	Keys   []Expr[T, V]
+	Values []Expr[T, V]
+}
+
+func (m *Map[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitMap(m)
+}
+
+type Variable[T, V any] struct {
+	Name lexer.Token
+}
+
+func (v *Variable[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitVariable(v)
+}
+
+// Lambda is an arrow function "(params) => body". Defaults[i] is the default
+// value of Params[i], or nil if it has none. A destructured parameter has its
+// pattern in Patterns[i] and its opening bracket in Params[i]. When Rest is
+// set the last parameter collects the remaining arguments into a list.
+type Lambda[T, V any] struct {
+	Paren    lexer.Token
+	Params   []lexer.Token
+	Defaults []Expr[T, V]
+	Patterns []Pattern[T, V]
+	Rest     bool
+	Arrow    lexer.Token
+	Body     Expr[T, V]
+}
+
+func (l *Lambda[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitLambda(l)
+}
+
+// Spread expands a list into the arguments of a call or the elements of a list
+type Spread[T, V any] struct {
+	Ellipsis   lexer.Token
+	Expression Expr[T, V]
+}
+
+func (s *Spread[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitSpread(s)
+}
+
+// Match evaluates the Body of the first arm whose Pattern matches Subject and
+// whose Guard, if any, is truthy
+type Match[T, V any] struct {
+	Keyword lexer.Token
+	Subject Expr[T, V]
+	Arms    []*MatchArm[T, V]
+}
+
+type MatchArm[T, V any] struct {
+	Case    lexer.Token
+	Pattern Pattern[T, V]
+	Guard   Expr[T, V]
+	Body    Expr[T, V]
+}
+
+func (m *Match[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitMatch(m)
+}
+
+// OptionalChain is a chain of gets, calls and indexes containing "?.". It
+// evaluates to nil when one of its optional gets is applied to nil.
+type OptionalChain[T, V any] struct {
+	Expression Expr[T, V]
+}
+
+func (oc *OptionalChain[T, V]) Accept(visitor ExprVisitor[T, V]) V {
+	return visitor.VisitOptionalChain(oc)
+}
+
 type AstPrinter struct{}
 
 // printer should return a string so it implementst the Expr[T=string] interface
@@ -106,6 +273,107 @@ func (astp AstPrinter) VisitComma(c *Comma[any, string]) string {
 func (astp AstPrinter) VisitTernary(t *Ternary[any, string]) string {
 	return printHelper(astp, "?:", t.Left, t.Middle, t.Right)
 }
+func (astp AstPrinter) VisitInterpolation(in *Interpolation[any, string]) string {
+	// the text between the embedded expressions is quoted so that its spaces
+	// are told apart from the ones between the parts
+	sb := strings.Builder{}
+	sb.WriteString("(interpolate")
+	for _, part := range in.Parts {
+		sb.WriteString(" ")
+		if lit, ok := part.(*Literal[any, string]); ok && lit.Token.Type == lexer.TokenInterpolation {
+			sb.WriteString(strconv.Quote(fmt.Sprint(lit.Value)))
+			continue
+		}
+		sb.WriteString(part.Accept(astp))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+func (astp AstPrinter) VisitList(li *List[any, string]) string {
+	return printHelper(astp, "list", li.Elements...)
+}
+func (astp AstPrinter) VisitIndex(ix *Index[any, string]) string {
+	return printHelper(astp, "index", ix.Object, ix.Index)
+}
+func (astp AstPrinter) VisitSlice(sl *Slice[any, string]) string {
+	start, end := "_", "_"
+	if sl.Start != nil {
+		start = sl.Start.Accept(astp)
+	}
+	if sl.End != nil {
+		end = sl.End.Accept(astp)
+	}
+	return "(slice " + sl.Object.Accept(astp) + " " + start + " " + end + ")"
+}
+func (astp AstPrinter) VisitSetIndex(si *SetIndex[any, string]) string {
+	if si.Value == nil {
+		if si.Prefix {
+			return printHelper(astp, si.Operator.Lexeme, si.Target)
+		}
+		return printHelper(astp, "post"+si.Operator.Lexeme, si.Target)
+	}
+	return printHelper(astp, si.Operator.Lexeme, si.Target, si.Value)
+}
+func (astp AstPrinter) VisitGet(g *Get[any, string]) string {
+	if g.Optional {
+		return "(?. " + g.Object.Accept(astp) + " " + g.Name.Lexeme + ")"
+	}
+	return "(. " + g.Object.Accept(astp) + " " + g.Name.Lexeme + ")"
+}
+func (astp AstPrinter) VisitCall(c *Call[any, string]) string {
+	return printHelper(astp, "call", append([]Expr[any, string]{c.Callee}, c.Arguments...)...)
+}
+func (astp AstPrinter) VisitMap(m *Map[any, string]) string {
+	entries := make([]Expr[any, string], 0, 2*len(m.Keys))
+	for idx := range m.Keys {
+		entries = append(entries, m.Keys[idx], m.Values[idx])
+	}
+	return printHelper(astp, "map", entries...)
+}
+func (astp AstPrinter) VisitVariable(v *Variable[any, string]) string {
+	return v.Name.Lexeme
+}
+func (astp AstPrinter) VisitLambda(l *Lambda[any, string]) string {
+	params := make([]string, 0, len(l.Params))
+	for idx, param := range l.Params {
+		name := param.Lexeme
+		if l.Patterns[idx] != nil {
+			name = patternString(l.Patterns[idx], func(e Expr[any, string]) string {
+				return e.Accept(astp)
+			})
+		}
+		switch {
+		case l.Rest && idx == len(l.Params)-1:
+			params = append(params, "..."+name)
+		case l.Defaults[idx] != nil:
+			params = append(params, "(= "+name+" "+l.Defaults[idx].Accept(astp)+")")
+		default:
+			params = append(params, name)
+		}
+	}
+	return "(lambda (" + strings.Join(params, " ") + ") " + l.Body.Accept(astp) + ")"
+}
+func (astp AstPrinter) VisitSpread(s *Spread[any, string]) string {
+	return printHelper(astp, "...", s.Expression)
+}
+func (astp AstPrinter) VisitMatch(m *Match[any, string]) string {
+	sb := strings.Builder{}
+	sb.WriteString("(match " + m.Subject.Accept(astp))
+	for _, arm := range m.Arms {
+		sb.WriteString(" (case " + patternString(arm.Pattern, func(e Expr[any, string]) string {
+			return e.Accept(astp)
+		}))
+		if arm.Guard != nil {
+			sb.WriteString(" (if " + arm.Guard.Accept(astp) + ")")
+		}
+		sb.WriteString(" " + arm.Body.Accept(astp) + ")")
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+func (astp AstPrinter) VisitOptionalChain(oc *OptionalChain[any, string]) string {
+	return printHelper(astp, "chain", oc.Expression)
+}
 func printHelper(astp ExprVisitor[any, string], operation string, exprArgs ...Expr[any, string]) string {
 	sb := strings.Builder{}
 	sb.WriteString("(")
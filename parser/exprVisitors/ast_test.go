@@ -0,0 +1,29 @@
+package exprVisitors_test
+
+import (
+	"testing"
+
+	"github/goInterpreter/parser"
+	"github/goInterpreter/parser/exprVisitors"
+)
+
+func TestAstPrinter(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"string literal", `"a b"`, "a b"},
+		{"interpolation", `"a ${1+2} b"`, `(interpolate "a " (+ 1.0 2.0) " b")`},
+		{"string in an interpolation", `"${"x"}!"`, `(interpolate x "!")`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, _ := parse(t, tt.source, false)
+			got := parser.TransformToStringAST(expr).Accept(exprVisitors.AstPrinter{})
+			if got != tt.want {
+				t.Errorf("printing %q:\ngot  %s\nwant %s", tt.source, got, tt.want)
+			}
+		})
+	}
+}
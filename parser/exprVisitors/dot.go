@@ -0,0 +1,240 @@
+package exprVisitors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DotPrinter renders an expression tree as a Graphviz digraph. Every visit
+// writes the node (and the edges to its children) and returns the node's id
+// so that the parent can link to it.
+type DotPrinter struct {
+	// when Annotate is set the expression is evaluated once, with Interpreter
+	// if given, and every node it reaches is labeled with its value
+	Annotate    bool
+	Interpreter *Interpreter
+	sb          strings.Builder
+	nextID      int
+	annotations map[Expr[any, interface{}]]string
+	// the bodies of lambdas and match arms are not annotated as they run once
+	// per call, if at all
+	bodyDepth int
+}
+
+func (d *DotPrinter) Print(expr Expr[any, interface{}]) string {
+	d.sb.Reset()
+	d.nextID = 0
+	if d.Annotate && expr != nil {
+		d.annotate(expr)
+	}
+	d.sb.WriteString("digraph AST {\n")
+	d.sb.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	if expr != nil {
+		expr.Accept(d)
+	}
+	d.sb.WriteString("}\n")
+	return d.sb.String()
+}
+
+func (d *DotPrinter) VisitBinary(bin *Binary[any, interface{}]) interface{} {
+	id := d.node(bin, "Binary", bin.Operator.Lexeme)
+	d.edge(id, bin.Left, "left")
+	d.edge(id, bin.Right, "right")
+	return id
+}
+func (d *DotPrinter) VisitUnary(un *Unary[any, interface{}]) interface{} {
+	id := d.node(un, "Unary", un.Operator.Lexeme)
+	d.edge(id, un.Right, "right")
+	return id
+}
+func (d *DotPrinter) VisitGrouping(gr *Grouping[any, interface{}]) interface{} {
+	id := d.node(gr, "Grouping", "")
+	d.edge(id, gr.Expression, "expression")
+	return id
+}
+func (d *DotPrinter) VisitLiteral(lit *Literal[any, interface{}]) interface{} {
+	// labeled like the value it evaluates to, so integers have no fraction
+	value := (&Interpreter{}).VisitLiteral(lit)
+	return d.node(lit, "Literal", repr(value, make(map[interface{}]bool)))
+}
+func (d *DotPrinter) VisitComma(c *Comma[any, interface{}]) interface{} {
+	id := d.node(c, "Comma", ",")
+	d.edge(id, c.Left, "left")
+	d.edge(id, c.Right, "right")
+	return id
+}
+func (d *DotPrinter) VisitTernary(t *Ternary[any, interface{}]) interface{} {
+	id := d.node(t, "Ternary", "?:")
+	d.edge(id, t.Left, "left")
+	d.edge(id, t.Middle, "middle")
+	d.edge(id, t.Right, "right")
+	return id
+}
+
+func (d *DotPrinter) VisitInterpolation(in *Interpolation[any, interface{}]) interface{} {
+	id := d.node(in, "Interpolation", "")
+	for idx, part := range in.Parts {
+		d.edge(id, part, fmt.Sprintf("part %d", idx))
+	}
+	return id
+}
+
+func (d *DotPrinter) VisitList(li *List[any, interface{}]) interface{} {
+	id := d.node(li, "List", "")
+	for idx, element := range li.Elements {
+		d.edge(id, element, fmt.Sprintf("element %d", idx))
+	}
+	return id
+}
+func (d *DotPrinter) VisitIndex(ix *Index[any, interface{}]) interface{} {
+	id := d.node(ix, "Index", "")
+	d.edge(id, ix.Object, "object")
+	d.edge(id, ix.Index, "index")
+	return id
+}
+func (d *DotPrinter) VisitSlice(sl *Slice[any, interface{}]) interface{} {
+	id := d.node(sl, "Slice", "")
+	d.edge(id, sl.Object, "object")
+	d.edge(id, sl.Start, "start")
+	d.edge(id, sl.End, "end")
+	return id
+}
+func (d *DotPrinter) VisitSetIndex(si *SetIndex[any, interface{}]) interface{} {
+	detail := si.Operator.Lexeme
+	if si.Value == nil && !si.Prefix {
+		detail = "post" + detail
+	}
+	id := d.node(si, "SetIndex", detail)
+	d.edge(id, si.Target, "target")
+	d.edge(id, si.Value, "value")
+	return id
+}
+func (d *DotPrinter) VisitGet(g *Get[any, interface{}]) interface{} {
+	detail := g.Name.Lexeme
+	if g.Optional {
+		detail = "?." + detail
+	}
+	id := d.node(g, "Get", detail)
+	d.edge(id, g.Object, "object")
+	return id
+}
+func (d *DotPrinter) VisitCall(c *Call[any, interface{}]) interface{} {
+	id := d.node(c, "Call", "")
+	d.edge(id, c.Callee, "callee")
+	for idx, argument := range c.Arguments {
+		d.edge(id, argument, fmt.Sprintf("argument %d", idx))
+	}
+	return id
+}
+func (d *DotPrinter) VisitMap(m *Map[any, interface{}]) interface{} {
+	id := d.node(m, "Map", "")
+	for idx := range m.Keys {
+		d.edge(id, m.Keys[idx], fmt.Sprintf("key %d", idx))
+		d.edge(id, m.Values[idx], fmt.Sprintf("value %d", idx))
+	}
+	return id
+}
+func (d *DotPrinter) VisitVariable(v *Variable[any, interface{}]) interface{} {
+	return d.node(v, "Variable", v.Name.Lexeme)
+}
+func (d *DotPrinter) VisitLambda(l *Lambda[any, interface{}]) interface{} {
+	params := make([]string, 0, len(l.Params))
+	for idx, param := range l.Params {
+		switch {
+		case l.Patterns[idx] != nil:
+			params = append(params, patternString(l.Patterns[idx], formatExpr))
+		case l.Rest && idx == len(l.Params)-1:
+			params = append(params, "..."+param.Lexeme)
+		default:
+			params = append(params, param.Lexeme)
+		}
+	}
+	id := d.node(l, "Lambda", "("+strings.Join(params, ", ")+") =>")
+	d.bodyDepth++
+	for idx, def := range l.Defaults {
+		d.edge(id, def, "default "+strings.TrimPrefix(params[idx], "..."))
+	}
+	d.edge(id, l.Body, "body")
+	d.bodyDepth--
+	return id
+}
+func (d *DotPrinter) VisitMatch(m *Match[any, interface{}]) interface{} {
+	id := d.node(m, "Match", "")
+	d.edge(id, m.Subject, "subject")
+	for idx, arm := range m.Arms {
+		pattern := patternString(arm.Pattern, formatExpr)
+		// arms are not expressions so they are not annotated
+		armID := d.node(nil, "Arm", "case "+pattern)
+		d.bodyDepth++
+		d.edge(armID, arm.Guard, "guard")
+		d.edge(armID, arm.Body, "body")
+		d.bodyDepth--
+		fmt.Fprintf(&d.sb, "\t%s -> %s [label=\"arm %d\"];\n", id, armID, idx)
+	}
+	return id
+}
+func (d *DotPrinter) VisitOptionalChain(oc *OptionalChain[any, interface{}]) interface{} {
+	id := d.node(oc, "OptionalChain", "")
+	d.edge(id, oc.Expression, "expression")
+	return id
+}
+func (d *DotPrinter) VisitSpread(s *Spread[any, interface{}]) interface{} {
+	id := d.node(s, "Spread", "...")
+	d.edge(id, s.Expression, "expression")
+	return id
+}
+
+// node writes a single labeled node and returns its id
+func (d *DotPrinter) node(expr Expr[any, interface{}], kind string, detail string) string {
+	id := fmt.Sprintf("n%d", d.nextID)
+	d.nextID++
+	label := kind
+	if detail != "" {
+		label += "\n" + detail
+	}
+	if annotation, ok := d.annotations[expr]; ok && d.bodyDepth == 0 {
+		label += "\n= " + annotation
+	}
+	fmt.Fprintf(&d.sb, "\t%s [label=\"%s\"];\n", id, dotEscape(label))
+	return id
+}
+
+// annotate evaluates expr, recording the value of every sub-expression as it
+// is computed since lists and maps may change afterwards
+func (d *DotPrinter) annotate(expr Expr[any, interface{}]) {
+	if d.Interpreter == nil {
+		d.Interpreter = &Interpreter{}
+	}
+	d.annotations = make(map[Expr[any, interface{}]]string)
+	d.Interpreter.trace = func(e Expr[any, interface{}], value interface{}) {
+		if err, ok := value.(error); ok {
+			d.annotations[e] = "error: " + err.Error()
+		} else {
+			d.annotations[e] = repr(value, make(map[interface{}]bool))
+		}
+	}
+	defer func() {
+		d.Interpreter.trace = nil
+	}()
+	d.Interpreter.evaluate(expr)
+}
+
+// edge visits the child and links it to the parent, labeling the edge by the child's role
+func (d *DotPrinter) edge(parentID string, child Expr[any, interface{}], role string) {
+	if child == nil {
+		return
+	}
+	childID := child.Accept(d).(string)
+	fmt.Fprintf(&d.sb, "\t%s -> %s [label=\"%s\"];\n", parentID, childID, role)
+}
+
+// formatExpr renders the expressions inside patterns
+func formatExpr(e Expr[any, interface{}]) string {
+	return strings.TrimSuffix((&Formatter{}).Format(e), "\n")
+}
+
+func dotEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	return strings.ReplaceAll(s, "\n", "\\n")
+}
@@ -0,0 +1,36 @@
+package exprVisitors
+
+import (
+	"fmt"
+
+	"github/goInterpreter/lexer"
+)
+
+// Environment binds names to values. Each call of a function gets its own
+// environment enclosed by the one the function was created in.
+type Environment struct {
+	values    map[string]interface{}
+	enclosing *Environment
+}
+
+func NewEnvironment(enclosing *Environment) *Environment {
+	return &Environment{
+		values:    make(map[string]interface{}),
+		enclosing: enclosing,
+	}
+}
+
+func (e *Environment) Define(name string, value interface{}) {
+	e.values[name] = value
+}
+
+// Get looks name up from the innermost environment outwards. A nil
+// environment is empty.
+func (e *Environment) Get(name lexer.Token) (interface{}, error) {
+	for env := e; env != nil; env = env.enclosing {
+		if value, ok := env.values[name.Lexeme]; ok {
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("Undefined variable '%s'.", name.Lexeme)
+}
@@ -0,0 +1,702 @@
+package exprVisitors
+
+import (
+	"strings"
+
+	"github/goInterpreter/lexer"
+)
+
+// binding power of every expression kind, mirroring the descent order in parser.Parser
+const (
+	precComma = iota + 1
+	precAssignment
+	precTernary
+	precCoalesce
+	precEquality
+	precComparison
+	precBitOr
+	precBitXor
+	precBitAnd
+	precShift
+	precTerm
+	precFactor
+	precExpo
+	precUnary
+	precPostfix
+	precPrimary
+)
+
+// lines broken by a comment in the middle of an expression are indented by
+// one level, as are the arms of a match
+const formatIndent = "    "
+
+// Formatter re-emits an expression tree in canonical style: single spaces
+// around binary operators, no space after unary operators and only the
+// parentheses that the operator precedence requires.
+type Formatter struct {
+	// Tokens maps every node to the tokens it owns in source order (see
+	// cst.Node.Tokens). When set the comments attached to those tokens are kept.
+	Tokens map[Expr[any, interface{}]][]lexer.Token
+	// EOF carries the comments that trail the expression
+	EOF          lexer.Token
+	sb           strings.Builder
+	lineStart    bool
+	pendingSpace bool
+	inExpr       bool
+	// the indentation levels of the current block of lines, of which
+	// continuation lines get one more, and of the line being written
+	indent     int
+	lineIndent int
+	// blockLine is set while the current line starts a block line, such as a
+	// match arm, rather than continuing the one before it
+	blockLine bool
+	// comments of dropped tokens waiting to be written before the next code
+	deferred []lexer.Trivia
+	// guard is set while writing a match guard outside of any brackets, where
+	// a lambda keeps its parentheses (see parser.Parser)
+	guard bool
+	// trial is set on the formatter that lays out the inside of brackets on
+	// one line to find out whether a comment breaks it (see broken)
+	trial bool
+}
+
+func (f *Formatter) Format(expr Expr[any, interface{}]) string {
+	f.sb.Reset()
+	f.lineStart = true
+	f.pendingSpace = false
+	f.inExpr = false
+	f.indent = 0
+	f.lineIndent = 0
+	f.blockLine = false
+	f.deferred = nil
+	f.guard = false
+	if expr != nil {
+		f.operand(expr, precComma)
+	}
+	f.inExpr = false
+	f.flush()
+	f.comments(f.EOF.Leading, false)
+	if !f.lineStart {
+		f.newline()
+	}
+	return f.sb.String()
+}
+
+func (f *Formatter) VisitBinary(bin *Binary[any, interface{}]) interface{} {
+	prec := precedence(bin)
+	leftPrec, rightPrec := prec, prec+1
+	if prec == precExpo {
+		// ** is right-associative and its left operand is parsed as a unary
+		leftPrec, rightPrec = precUnary, precExpo
+	}
+	f.operand(bin.Left, leftPrec)
+	f.space()
+	f.token(bin, 0, bin.Operator.Lexeme)
+	f.space()
+	f.operand(bin.Right, rightPrec)
+	return nil
+}
+func (f *Formatter) VisitUnary(un *Unary[any, interface{}]) interface{} {
+	f.token(un, 0, un.Operator.Lexeme)
+	if un.Operator.Type == lexer.TokenMinus && startsWithMinus(un.Right) {
+		// keep "- -x" apart so it is not read back as a single token
+		f.space()
+	}
+	f.operand(un.Right, precUnary)
+	return nil
+}
+func (f *Formatter) VisitGrouping(gr *Grouping[any, interface{}]) interface{} {
+	f.token(gr, 0, "(")
+	f.operand(gr.Expression, precComma)
+	f.token(gr, 1, ")")
+	return nil
+}
+func (f *Formatter) VisitLiteral(lit *Literal[any, interface{}]) interface{} {
+	f.token(lit, 0, lit.Token.Lexeme)
+	return nil
+}
+func (f *Formatter) VisitComma(c *Comma[any, interface{}]) interface{} {
+	f.operand(c.Left, precComma)
+	f.token(c, 0, ",")
+	f.space()
+	f.operand(c.Right, precAssignment)
+	return nil
+}
+func (f *Formatter) VisitTernary(t *Ternary[any, interface{}]) interface{} {
+	f.operand(t.Left, precCoalesce)
+	f.space()
+	f.token(t, 0, "?")
+	f.space()
+	f.operand(t.Middle, precComma)
+	f.space()
+	f.token(t, 1, ":")
+	f.space()
+	f.operand(t.Right, precTernary)
+	return nil
+}
+func (f *Formatter) VisitInterpolation(in *Interpolation[any, interface{}]) interface{} {
+	// the owned tokens are the string, then the end of every embedded
+	// expression carrying the comments before its closing brace
+	owned := f.Tokens[in]
+	if len(owned) > 0 {
+		f.comments(owned[0].Leading, false)
+	}
+	defer f.enclosed()()
+	f.write("\"")
+	embedded := 1
+	for idx, part := range in.Token.Literal.([]lexer.InterpolationPart) {
+		if part.Tokens == nil {
+			f.write(part.Raw)
+			continue
+		}
+		f.write("${")
+		f.operand(in.Parts[idx], precComma)
+		f.token(in, embedded, "")
+		embedded++
+		f.write("}")
+	}
+	f.write("\"")
+	if len(owned) > 0 {
+		f.comments(owned[0].Trailing, true)
+	}
+	return nil
+}
+func (f *Formatter) VisitList(li *List[any, interface{}]) interface{} {
+	f.elements(li, "[", li.Elements, "]")
+	return nil
+}
+func (f *Formatter) VisitIndex(ix *Index[any, interface{}]) interface{} {
+	defer f.enclosed()()
+	f.operand(ix.Object, precPrimary)
+	f.token(ix, 0, "[")
+	f.operand(ix.Index, precTernary)
+	f.token(ix, 1, "]")
+	return nil
+}
+func (f *Formatter) VisitSlice(sl *Slice[any, interface{}]) interface{} {
+	defer f.enclosed()()
+	f.operand(sl.Object, precPrimary)
+	f.token(sl, 0, "[")
+	if sl.Start != nil {
+		f.operand(sl.Start, precTernary)
+	}
+	f.token(sl, 1, ":")
+	if sl.End != nil {
+		f.operand(sl.End, precTernary)
+	}
+	f.token(sl, 2, "]")
+	return nil
+}
+func (f *Formatter) VisitSetIndex(si *SetIndex[any, interface{}]) interface{} {
+	switch {
+	case si.Value != nil:
+		f.operand(si.Target, precPrimary)
+		f.space()
+		f.token(si, 0, si.Operator.Lexeme)
+		f.space()
+		f.operand(si.Value, precAssignment)
+	case si.Prefix:
+		f.token(si, 0, si.Operator.Lexeme)
+		f.operand(si.Target, precPrimary)
+	default:
+		f.operand(si.Target, precPrimary)
+		f.token(si, 0, si.Operator.Lexeme)
+	}
+	return nil
+}
+func (f *Formatter) VisitGet(g *Get[any, interface{}]) interface{} {
+	f.operand(g.Object, precPrimary)
+	if g.Optional {
+		f.token(g, 0, "?.")
+	} else {
+		f.token(g, 0, ".")
+	}
+	f.token(g, 1, g.Name.Lexeme)
+	return nil
+}
+func (f *Formatter) VisitCall(c *Call[any, interface{}]) interface{} {
+	f.operand(c.Callee, precPrimary)
+	f.elements(c, "(", c.Arguments, ")")
+	return nil
+}
+func (f *Formatter) VisitMap(m *Map[any, interface{}]) interface{} {
+	// the owned tokens are '{', then ':' and ',' alternating, then '}'
+	defer f.enclosed()()
+	entry := func(f *Formatter, idx int) {
+		f.operand(m.Keys[idx], precTernary)
+		f.token(m, 2*idx+1, ":")
+		f.space()
+		f.operand(m.Values[idx], precAssignment)
+	}
+	f.bracketed(m, "{", len(m.Keys), 2, entry, "}")
+	return nil
+}
+func (f *Formatter) VisitVariable(v *Variable[any, interface{}]) interface{} {
+	f.token(v, 0, v.Name.Lexeme)
+	return nil
+}
+func (f *Formatter) VisitLambda(l *Lambda[any, interface{}]) interface{} {
+	// the owned tokens are '(', the parameters separated by ',' with their
+	// "..." or '=' and the tokens of their patterns, then ')' and "=>"
+	index := 0
+	next := func(text string) {
+		f.token(l, index, text)
+		index++
+	}
+	next("(")
+	for idx, param := range l.Params {
+		if idx > 0 {
+			next(",")
+			f.space()
+		}
+		if l.Rest && idx == len(l.Params)-1 {
+			next("...")
+		}
+		if l.Patterns[idx] != nil {
+			f.pattern(l.Patterns[idx], next)
+		} else {
+			next(param.Lexeme)
+		}
+		if l.Defaults[idx] != nil {
+			f.space()
+			next("=")
+			f.space()
+			f.operand(l.Defaults[idx], precAssignment)
+		}
+	}
+	if len(l.Params) > 0 && len(f.Tokens[l]) == index+3 {
+		// a trailing comma
+		next("")
+	}
+	next(")")
+	f.space()
+	next("=>")
+	f.space()
+	f.operand(l.Body, precAssignment)
+	return nil
+}
+func (f *Formatter) VisitMatch(m *Match[any, interface{}]) interface{} {
+	// the owned tokens are the keywords, braces and arrows of the match and the
+	// tokens of its patterns other than their literals, all in source order
+	defer f.enclosed()()
+	index := 0
+	next := func(text string) {
+		f.token(m, index, text)
+		index++
+	}
+	next("match")
+	f.space()
+	f.operand(m.Subject, precTernary)
+	f.space()
+	next("{")
+	// every arm starts a line one level in from the line of the match
+	outer, base := f.indent, f.lineIndent
+	f.indent = base + 1
+	for _, arm := range m.Arms {
+		f.blockNewline()
+		next("case")
+		f.space()
+		f.pattern(arm.Pattern, next)
+		if arm.Guard != nil {
+			f.space()
+			next("if")
+			f.space()
+			f.guard = true
+			f.operand(arm.Guard, precAssignment)
+			f.guard = false
+		}
+		f.space()
+		next("=>")
+		f.space()
+		f.operand(arm.Body, precAssignment)
+	}
+	f.indent = base
+	if len(m.Arms) > 0 {
+		f.blockNewline()
+	}
+	next("}")
+	f.indent = outer
+	return nil
+}
+
+// pattern writes a match pattern, emitting its own tokens through next
+func (f *Formatter) pattern(p Pattern[any, interface{}], next func(string)) {
+	switch pt := p.(type) {
+	case *LiteralPattern[any, interface{}]:
+		f.operand(pt.Value, precUnary)
+	case *WildcardPattern[any, interface{}]:
+		next("_")
+	case *BindingPattern[any, interface{}]:
+		next(pt.Name.Lexeme)
+	case *ListPattern[any, interface{}]:
+		next("[")
+		for idx, element := range pt.Elements {
+			if idx > 0 {
+				next(",")
+				f.space()
+			}
+			f.pattern(element, next)
+		}
+		if pt.Rest != nil {
+			if len(pt.Elements) > 0 {
+				next(",")
+				f.space()
+			}
+			next("...")
+			next(pt.Rest.Lexeme)
+		}
+		next("]")
+	case *MapPattern[any, interface{}]:
+		next("{")
+		for idx := range pt.Keys {
+			if idx > 0 {
+				next(",")
+				f.space()
+			}
+			if pt.Keys[idx] != nil {
+				f.operand(pt.Keys[idx], precUnary)
+				next(":")
+				f.space()
+			}
+			f.pattern(pt.Values[idx], next)
+		}
+		if pt.Rest != nil {
+			if len(pt.Keys) > 0 {
+				next(",")
+				f.space()
+			}
+			next("...")
+			next(pt.Rest.Lexeme)
+		}
+		next("}")
+	case *DefaultPattern[any, interface{}]:
+		f.pattern(pt.Pattern, next)
+		f.space()
+		next("=")
+		f.space()
+		f.operand(pt.Default, precAssignment)
+	}
+}
+func (f *Formatter) VisitOptionalChain(oc *OptionalChain[any, interface{}]) interface{} {
+	oc.Expression.Accept(f)
+	return nil
+}
+func (f *Formatter) VisitSpread(s *Spread[any, interface{}]) interface{} {
+	f.token(s, 0, "...")
+	f.operand(s.Expression, precAssignment)
+	return nil
+}
+
+// elements writes a bracketed, comma separated list of expressions. The
+// tokens owned by node are the opening bracket, the commas and the closing
+// bracket.
+func (f *Formatter) elements(node Expr[any, interface{}], open string, elements []Expr[any, interface{}], close string) {
+	defer f.enclosed()()
+	element := func(f *Formatter, idx int) {
+		f.operand(elements[idx], precAssignment)
+	}
+	f.bracketed(node, open, len(elements), 1, element, close)
+}
+
+// bracketed writes the count items of node, each written to f by item,
+// separated by commas and enclosed by the open and close brackets. Every item
+// owns stride tokens of node counting the comma before it. On one line a
+// trailing comma is dropped but not the comments attached to it. When a line
+// comment inside the brackets breaks the line, every item gets a line of its
+// own one level in, followed by a comma, and the closing bracket goes back to
+// the indentation of the line it was opened on.
+func (f *Formatter) bracketed(node Expr[any, interface{}], open string, count, stride int, item func(f *Formatter, idx int), close string) {
+	// the index of the closing bracket, after a trailing comma if there is one
+	last := stride * count
+	trailing := count > 0 && len(f.Tokens[node])-2 == last
+	if count == 0 {
+		last = 1
+	} else if trailing {
+		last++
+	}
+	broken := f.broken(node, func(trial *Formatter) {
+		trial.bracketed(node, open, count, stride, item, close)
+	})
+	f.token(node, 0, open)
+	if !broken {
+		for idx := 0; idx < count; idx++ {
+			if idx > 0 {
+				f.token(node, stride*idx, ",")
+				f.space()
+			}
+			item(f, idx)
+		}
+		if trailing {
+			f.token(node, last-1, "")
+		}
+		f.token(node, last, close)
+		return
+	}
+	outer, base := f.indent, f.lineIndent
+	f.indent = base + 1
+	for idx := 0; idx < count; idx++ {
+		f.blockNewline()
+		item(f, idx)
+		switch {
+		case idx < count-1:
+			f.token(node, stride*(idx+1), ",")
+		case trailing:
+			f.token(node, last-1, ",")
+		default:
+			f.write(",")
+		}
+	}
+	f.indent = base
+	f.blockNewline()
+	f.token(node, last, close)
+	f.indent = outer
+}
+
+// broken reports whether a line comment inside the brackets of node ends a
+// line when write lays out the node on one line of a trial formatter
+func (f *Formatter) broken(node Expr[any, interface{}], write func(trial *Formatter)) bool {
+	owned := f.Tokens[node]
+	if f.trial || len(owned) == 0 {
+		return false
+	}
+	// the comments before the opening bracket and after the closing one are
+	// outside of the brackets
+	inside := append([]lexer.Token(nil), owned...)
+	inside[0].Leading = nil
+	inside[len(inside)-1].Trailing = nil
+	f.Tokens[node] = inside
+	defer func() {
+		f.Tokens[node] = owned
+	}()
+	trial := Formatter{
+		Tokens: f.Tokens,
+		guard:  f.guard,
+		trial:  true,
+	}
+	write(&trial)
+	return strings.Contains(trial.sb.String(), "\n")
+}
+
+// operand formats expr in a position that needs at least minPrec, dropping
+// any grouping that the precedence makes redundant
+func (f *Formatter) operand(expr Expr[any, interface{}], minPrec int) {
+	groups := make([]*Grouping[any, interface{}], 0)
+	inner := expr
+	for {
+		gr, ok := inner.(*Grouping[any, interface{}])
+		if !ok {
+			break
+		}
+		groups = append(groups, gr)
+		inner = gr.Expression
+	}
+	_, lambda := inner.(*Lambda[any, interface{}])
+	parens := precedence(inner) < minPrec || f.guard && lambda
+	if parens {
+		defer f.enclosed()()
+	}
+	if parens && len(groups) == 0 {
+		f.write("(")
+	}
+	// redundant parentheses go away but not the comments attached to them
+	for i, gr := range groups {
+		if parens && i == len(groups)-1 {
+			f.token(gr, 0, "(")
+		} else {
+			f.token(gr, 0, "")
+		}
+	}
+	inner.Accept(f)
+	for i := len(groups) - 1; i >= 0; i-- {
+		if parens && i == len(groups)-1 {
+			f.token(groups[i], 1, ")")
+		} else {
+			f.token(groups[i], 1, "")
+		}
+	}
+	if parens && len(groups) == 0 {
+		f.write(")")
+	}
+}
+
+// enclosed clears guard for code inside brackets and returns a function that
+// restores it
+func (f *Formatter) enclosed() func() {
+	guard := f.guard
+	f.guard = false
+	return func() {
+		f.guard = guard
+	}
+}
+
+// token writes the text of the index-th token owned by node surrounded by the
+// comments that were attached to it in the source. A token written as "" is
+// dropped: its comments stay on the line of the code before it while that line
+// is open, and otherwise move in front of the code after it, where formatting
+// the output again will find them.
+func (f *Formatter) token(node Expr[any, interface{}], index int, text string) {
+	owned := f.Tokens[node]
+	if index >= len(owned) {
+		f.write(text)
+		return
+	}
+	if text == "" {
+		f.deferred = append(f.deferred, owned[index].Leading...)
+		if f.lineStart {
+			f.deferred = append(f.deferred, owned[index].Trailing...)
+		} else {
+			f.comments(owned[index].Trailing, true)
+		}
+		return
+	}
+	f.flush()
+	f.comments(owned[index].Leading, false)
+	f.write(text)
+	f.comments(owned[index].Trailing, true)
+}
+
+// flush writes the comments of the dropped tokens before the code that follows them
+func (f *Formatter) flush() {
+	deferred := f.deferred
+	f.deferred = nil
+	f.comments(deferred, false)
+}
+
+// comments writes every comment in trivia. Block comments stay inline, line
+// comments end the line: trailing ones stay on the line of their token while
+// leading ones start a new line.
+func (f *Formatter) comments(trivia []lexer.Trivia, trailing bool) {
+	for _, tr := range trivia {
+		if tr.Kind != lexer.TriviaComment {
+			continue
+		}
+		if strings.HasPrefix(tr.Text, "/*") {
+			f.space()
+			f.emit(tr.Text)
+			f.space()
+			continue
+		}
+		if !f.lineStart {
+			if trailing {
+				f.pendingSpace = true
+			} else {
+				f.newline()
+			}
+		}
+		f.emit(strings.TrimRight(tr.Text, " \t\r"))
+		f.newline()
+	}
+}
+
+// write emits a piece of code
+func (f *Formatter) write(text string) {
+	if text == "" {
+		return
+	}
+	f.flush()
+	f.emit(text)
+	f.inExpr = true
+	f.blockLine = false
+}
+
+func (f *Formatter) emit(text string) {
+	if f.lineStart && f.inExpr {
+		f.lineIndent = f.indent
+		if !f.blockLine {
+			f.lineIndent++
+		}
+		f.sb.WriteString(strings.Repeat(formatIndent, f.lineIndent))
+	} else if f.pendingSpace && !f.lineStart {
+		f.sb.WriteByte(' ')
+	}
+	f.sb.WriteString(text)
+	f.lineStart = false
+	f.pendingSpace = false
+}
+
+func (f *Formatter) space() {
+	f.pendingSpace = true
+}
+
+func (f *Formatter) newline() {
+	f.sb.WriteByte('\n')
+	f.lineStart = true
+	f.pendingSpace = false
+	f.lineIndent = 0
+}
+
+// blockNewline starts a block line unless a comment has just ended the line.
+// Comments before the first code on a block line are indented like it.
+func (f *Formatter) blockNewline() {
+	if !f.lineStart {
+		f.newline()
+	}
+	f.blockLine = true
+}
+
+func ungroup(expr Expr[any, interface{}]) Expr[any, interface{}] {
+	for {
+		gr, ok := expr.(*Grouping[any, interface{}])
+		if !ok {
+			return expr
+		}
+		expr = gr.Expression
+	}
+}
+
+func precedence(expr Expr[any, interface{}]) int {
+	switch e := expr.(type) {
+	case *Comma[any, interface{}]:
+		return precComma
+	case *Ternary[any, interface{}]:
+		return precTernary
+	case *Binary[any, interface{}]:
+		switch e.Operator.Type {
+		case lexer.TokenQuestionQuestion:
+			return precCoalesce
+		case lexer.TokenEqualEqual, lexer.TokenBangEqual:
+			return precEquality
+		case lexer.TokenGreater, lexer.TokenGreaterEqual, lexer.TokenLess, lexer.TokenLessEqual:
+			return precComparison
+		case lexer.TokenPipe:
+			return precBitOr
+		case lexer.TokenCaret:
+			return precBitXor
+		case lexer.TokenAmpersand:
+			return precBitAnd
+		case lexer.TokenLessLess, lexer.TokenGreaterGreater:
+			return precShift
+		case lexer.TokenPlus, lexer.TokenMinus:
+			return precTerm
+		case lexer.TokenStar, lexer.TokenSlash, lexer.TokenPercent, lexer.TokenTildeSlash:
+			return precFactor
+		case lexer.TokenStarStar:
+			return precExpo
+		}
+	case *Unary[any, interface{}]:
+		return precUnary
+	case *Lambda[any, interface{}]:
+		// the body extends as far to the right as an assignment would
+		return precAssignment
+	case *SetIndex[any, interface{}]:
+		switch {
+		case e.Value != nil:
+			return precAssignment
+		case e.Prefix:
+			return precUnary
+		}
+		return precPostfix
+	}
+	return precPrimary
+}
+
+// startsWithMinus reports whether the formatted expr begins with a '-' or '--' token
+func startsWithMinus(expr Expr[any, interface{}]) bool {
+	switch e := ungroup(expr).(type) {
+	case *Unary[any, interface{}]:
+		return e.Operator.Type == lexer.TokenMinus
+	case *SetIndex[any, interface{}]:
+		return e.Prefix && e.Operator.Type == lexer.TokenMinusMinus
+	}
+	return false
+}
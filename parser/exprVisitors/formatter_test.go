@@ -0,0 +1,105 @@
+package exprVisitors_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github/goInterpreter/lexer"
+	"github/goInterpreter/parser"
+	"github/goInterpreter/parser/cst"
+	"github/goInterpreter/parser/exprVisitors"
+)
+
+// parse lexes and parses source, failing the test on any error
+func parse(t *testing.T, source string, trivia bool) (exprVisitors.Expr[any, interface{}], lexer.TokenizedText) {
+	t.Helper()
+	lex := lexer.Lexer{
+		Reader: bytes.NewReader([]byte(source)),
+		Line:   1,
+		Lexeme: bytes.NewBuffer(nil),
+		Trivia: trivia,
+	}
+	toks, tokErrs, err := lex.ScanTokens()
+	if err != nil || len(tokErrs) != 0 {
+		t.Fatalf("lexing %q failed: %v %s", source, err, tokErrs.ToString())
+	}
+	p := parser.Parser{
+		Tokens: toks,
+	}
+	expr := p.Parse()
+	if p.HadError {
+		t.Fatalf("parsing %q failed", source)
+	}
+	return expr, toks
+}
+
+// format formats source the way the fmt command does, keeping its comments
+func format(t *testing.T, source string) string {
+	t.Helper()
+	expr, toks := parse(t, source, true)
+	tree, err := cst.Build(expr, toks)
+	if err != nil {
+		t.Fatalf("cst.Build(%q) failed: %s", source, err)
+	}
+	formatter := exprVisitors.Formatter{
+		Tokens: tree.Tokens(),
+		EOF:    toks[len(toks)-1],
+	}
+	return formatter.Format(expr)
+}
+
+func TestFormatIdempotent(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"operator spacing", "1+2*3", "1 + 2 * 3\n"},
+		{"needed parentheses", "(1+2)*3", "(1 + 2) * 3\n"},
+		{"redundant parentheses", "((1))+(2)", "1 + 2\n"},
+		{"double negation", "-(-1)", "- -1\n"},
+		{"right-associative power", "2**3**2", "2 ** 3 ** 2\n"},
+		{"grouped power", "(2**3)**2", "(2 ** 3) ** 2\n"},
+		{"nested ternary", "1?2:3?4:5", "1 ? 2 : 3 ? 4 : 5\n"},
+		{"grouped ternary", "(1?2:3)?4:5", "(1 ? 2 : 3) ? 4 : 5\n"},
+		{"coalesce in a ternary", "a ?? b ? c : d", "a ?? b ? c : d\n"},
+		{"trailing commas", "[1,2,3,]", "[1, 2, 3]\n"},
+		{"map", `{1:2,"a":[3],}`, "{1: 2, \"a\": [3]}\n"},
+		{"compound assignment", "[1][1:][0]+=1", "[1][1:][0] += 1\n"},
+		{"lambda", "(a,b)=>a+b", "(a, b) => a + b\n"},
+		{"default and rest parameters", "(a,b=1,...r)=>a+b", "(a, b = 1, ...r) => a + b\n"},
+		{"spread argument", "f(1,...xs,)", "f(1, ...xs)\n"},
+		{"match", `match x {case 1=>"one" case [a,...r] if a>1=>r case _=>nil}`, "match x {\n    case 1 => \"one\"\n    case [a, ...r] if a > 1 => r\n    case _ => nil\n}\n"},
+		{"empty match", "match x {}", "match x {}\n"},
+		{"lambda guard", "match 1 { case x if ((y) => y) => 1 }", "match 1 {\n    case x if ((y) => y) => 1\n}\n"},
+		{"lambda in a guard's ternary", "match 1 { case x if a ? ((y) => y) : b => 1 }", "match 1 {\n    case x if a ? ((y) => y) : b => 1\n}\n"},
+		{"negative map pattern key", "match x { case {-1: y} => y }", "match x {\n    case {-1: y} => y\n}\n"},
+		{"lambda in a guard's list", "match 1 { case x if [((y) => y)] => 1 }", "match 1 {\n    case x if [(y) => y] => 1\n}\n"},
+		{"interpolation", `"a\n${ x+1 }b"`, "\"a\\n${x + 1}b\"\n"},
+		{"trailing line comments", "1 // one\n+ 2 // two\n", "1 // one\n    + 2 // two\n"},
+		{"block comments", "/* lead */ 1 /* mid */ + 2", "/* lead */ 1 /* mid */ + 2\n"},
+		{"comment on its own line", "1 +\n// own line\n2", "1 +\n    // own line\n    2\n"},
+		{"comment in a match arm", "match x { case 1 => 1 + // c\n2 }", "match x {\n    case 1 => 1 + // c\n        2\n}\n"},
+		{"comments on dropped parentheses", "1 //c1\n+ //c2\n( //c3\n2 //c4\n* //c5\n3 //c6\n) //c7\n", "1 //c1\n    + //c2\n    //c3\n    2 //c4\n    * //c5\n    3 //c6\n//c7\n"},
+		{"comment after a dropped parenthesis", "(1 //a\n) //b\n+ 2", "1 //a\n    //b\n    + 2\n"},
+		{"comment on a dropped parenthesis stays on its line", "(1) // c\n+ 2", "1 // c\n    + 2\n"},
+		{"comment on a trailing comma", "[1, // c\n]", "[\n    1, // c\n]\n"},
+		{"comments breaking a list", "[\n  1, // one\n  2, // two\n]", "[\n    1, // one\n    2, // two\n]\n"},
+		{"comment after an opening bracket", "f( // c\n1, 2)", "f( // c\n    1,\n    2,\n)\n"},
+		{"comment breaking a map", "{1: 2, // c\n3: [4, 5]}", "{\n    1: 2, // c\n    3: [4, 5],\n}\n"},
+		{"comment breaking a nested list", "[[1, // c\n2], 3]", "[\n    [\n        1, // c\n        2,\n    ],\n    3,\n]\n"},
+		{"comment breaking a list in a match arm", "match x { case 1 => [1, // c\n2] }", "match x {\n    case 1 => [\n        1, // c\n        2,\n    ]\n}\n"},
+		{"comments around a list", "// a\n[1, 2] // b\n", "// a\n[1, 2] // b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := format(t, tt.source)
+			if first != tt.want {
+				t.Errorf("formatting %q:\ngot  %q\nwant %q", tt.source, first, tt.want)
+			}
+			if second := format(t, first); second != first {
+				t.Errorf("formatting %q again changed it:\nfirst  %q\nsecond %q", tt.source, first, second)
+			}
+		})
+	}
+}
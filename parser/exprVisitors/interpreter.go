@@ -5,14 +5,31 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"reflect"
+	"math/big"
 	"strconv"
+	"strings"
 
 	"github/goInterpreter/lexer"
 )
 
+type NumericPolicy int
+
+const (
+	// IEEE 754 semantics: 1/0 is Infinity and 0/0 is NaN
+	NumericIEEE NumericPolicy = iota
+	// division by zero and ** results that are not finite real numbers are runtime errors
+	NumericStrict
+)
+
 type Interpreter struct {
 	HadError bool
+	Numeric  NumericPolicy
+	// env holds the parameters of the functions being called
+	env *Environment
+	// depth counts the lambda calls in progress
+	depth int
+	// trace, when set, is called with the value of every expression evaluated
+	trace func(expr Expr[any, interface{}], value interface{})
 }
 
 func (i *Interpreter) VisitBinary(b *Binary[any, interface{}]) interface{} {
@@ -20,11 +37,22 @@ func (i *Interpreter) VisitBinary(b *Binary[any, interface{}]) interface{} {
 	if err, ok := left.(error); ok {
 		return err
 	}
+	if b.Operator.Type == lexer.TokenQuestionQuestion && left != nil {
+		// the right operand is only evaluated when the left one is nil
+		return left
+	}
 	right := i.evaluate(b.Right)
 	if err, ok := right.(error); ok {
 		return err
 	}
-	operator := b.Operator
+	if b.Operator.Type == lexer.TokenQuestionQuestion {
+		return right
+	}
+	return i.binary(b.Operator, left, right)
+}
+
+// binary applies a binary operator to two evaluated operands
+func (i *Interpreter) binary(operator lexer.Token, left, right interface{}) interface{} {
 	// operators that can have non-numeric operands
 	if operator.Type == lexer.TokenPlus {
 		if checkStringOperands(left, right) {
@@ -34,7 +62,7 @@ func (i *Interpreter) VisitBinary(b *Binary[any, interface{}]) interface{} {
 	if operator.Type == lexer.TokenEqualEqual {
 		ok, err := isEqual(left, right)
 		if err != nil {
-			errStr := fmt.Sprintf("[line %d] Error: invalid operation %v %s %v (mismatched types %s and %s)", operator.Line, left, operator.Lexeme, right, reflect.TypeOf(left).String(), reflect.TypeOf(right).String())
+			errStr := fmt.Sprintf("[line %d] Error: invalid operation %s %s %s (mismatched types %s and %s)", operator.Line, repr(left, make(map[interface{}]bool)), operator.Lexeme, repr(right, make(map[interface{}]bool)), typeName(left), typeName(right))
 			return errors.New(errStr)
 		}
 		return ok
@@ -42,42 +70,96 @@ func (i *Interpreter) VisitBinary(b *Binary[any, interface{}]) interface{} {
 	if operator.Type == lexer.TokenBangEqual {
 		ok, err := isEqual(left, right)
 		if err != nil {
-			errStr := fmt.Sprintf("[line %d] Error: invalid operation %v %s %v (mismatched types %s and %s)", operator.Line, left, operator.Lexeme, right, reflect.TypeOf(left).String(), reflect.TypeOf(right).String())
+			errStr := fmt.Sprintf("[line %d] Error: invalid operation %s %s %s (mismatched types %s and %s)", operator.Line, repr(left, make(map[interface{}]bool)), operator.Lexeme, repr(right, make(map[interface{}]bool)), typeName(left), typeName(right))
 			return errors.New(errStr)
 		}
 		return !ok
 	}
 	// all operators below are only defined for numeric operands (except TokenPlus which we already checked the string case)
 	if !checkNumberOperands(left, right) {
-		errStr := fmt.Sprintf("[line %d] Error: invalid operation %v %s %v (mismatched types %s and %s)", operator.Line, left, operator.Lexeme, right, reflect.TypeOf(left).String(), reflect.TypeOf(right).String())
+		errStr := fmt.Sprintf("[line %d] Error: invalid operation %s %s %s (mismatched types %s and %s)", operator.Line, repr(left, make(map[interface{}]bool)), operator.Lexeme, repr(right, make(map[interface{}]bool)), typeName(left), typeName(right))
 		return errors.New(errStr)
 	}
 	switch operator.Type {
+	case lexer.TokenGreater, lexer.TokenGreaterEqual, lexer.TokenLess, lexer.TokenLessEqual:
+		return compareNumbers(operator.Type, left, right)
+	case lexer.TokenTildeSlash, lexer.TokenPercent:
+		result, err := floorDivision(operator.Type, left, right)
+		if err == nil {
+			err = i.checkNumeric(operator, left, right, result)
+		}
+		if err != nil {
+			errStr := fmt.Sprintf("[line %d] Error: invalid operation %s %s %s (%s)", operator.Line, formatNumber(left), operator.Lexeme, formatNumber(right), err.Error())
+			return errors.New(errStr)
+		}
+		return result
+	case lexer.TokenAmpersand, lexer.TokenPipe, lexer.TokenCaret, lexer.TokenLessLess, lexer.TokenGreaterGreater:
+		if !isInteger(left) || !isInteger(right) {
+			errStr := fmt.Sprintf("[line %d] Error: invalid operation %s %s %s (operator %s is only defined on integers)", operator.Line, formatNumber(left), operator.Lexeme, formatNumber(right), operator.Lexeme)
+			return errors.New(errStr)
+		}
+		result, err := bitwise(operator.Type, left, right)
+		if err != nil {
+			errStr := fmt.Sprintf("[line %d] Error: invalid operation %s %s %s (%s)", operator.Line, formatNumber(left), operator.Lexeme, formatNumber(right), err.Error())
+			return errors.New(errStr)
+		}
+		return result
+	}
+	if isInteger(left) && isInteger(right) {
+		result, ok, err := integerArithmetic(operator.Type, left, right)
+		if err != nil {
+			errStr := fmt.Sprintf("[line %d] Error: invalid operation %s %s %s (%s)", operator.Line, formatNumber(left), operator.Lexeme, formatNumber(right), err.Error())
+			return errors.New(errStr)
+		}
+		if ok {
+			return result
+		}
+	}
+	// mixed operands and / are computed in floating point
+	l, r := toFloat(left), toFloat(right)
+	var result float64
+	switch operator.Type {
 	case lexer.TokenPlus:
-		return left.(float64) + right.(float64)
+		result = l + r
 	case lexer.TokenMinus:
-		return left.(float64) - right.(float64)
+		result = l - r
 
 	case lexer.TokenSlash:
-		return left.(float64) / right.(float64)
+		result = l / r
 
 	case lexer.TokenStar:
-		return left.(float64) * right.(float64)
+		result = l * r
 
 	case lexer.TokenStarStar:
-		return math.Pow(left.(float64), right.(float64))
-
-	case lexer.TokenGreater:
-		return left.(float64) > right.(float64)
-
-	case lexer.TokenGreaterEqual:
-		return left.(float64) >= right.(float64)
-
-	case lexer.TokenLess:
-		return left.(float64) < right.(float64)
+		result = math.Pow(l, r)
+	default:
+		return nil
+	}
+	if err := i.checkNumeric(operator, left, right, result); err != nil {
+		errStr := fmt.Sprintf("[line %d] Error: invalid operation %s %s %s (%s)", operator.Line, formatNumber(left), operator.Lexeme, formatNumber(right), err.Error())
+		return errors.New(errStr)
+	}
+	return result
+}
 
-	case lexer.TokenLessEqual:
-		return left.(float64) <= right.(float64)
+// checkNumeric rejects the floating point results that the strict numeric policy does not allow
+func (i *Interpreter) checkNumeric(operator lexer.Token, left, right, result interface{}) error {
+	value, ok := result.(float64)
+	if i.Numeric != NumericStrict || !ok {
+		return nil
+	}
+	switch operator.Type {
+	case lexer.TokenSlash, lexer.TokenTildeSlash, lexer.TokenPercent:
+		if toFloat(right) == 0 {
+			return errors.New("division by zero")
+		}
+	case lexer.TokenStarStar:
+		if toFloat(left) == 0 && toFloat(right) < 0 {
+			return errors.New("division by zero")
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return errors.New("result is not a finite real number")
+		}
 	}
 	return nil
 }
@@ -89,10 +171,16 @@ func (i *Interpreter) VisitUnary(un *Unary[any, interface{}]) interface{} {
 	}
 	switch operator.Type {
 	case lexer.TokenMinus:
-		if val, ok := operand.(float64); ok {
-			return -val
+		if isNumber(operand) {
+			return negate(operand)
 		}
-		errStr := fmt.Sprintf("[line %d] Error: invalid operation %v %s (operand must be numeric cannot be %s)", operator.Line, operand, operator.Lexeme, reflect.TypeOf(operand).String())
+		errStr := fmt.Sprintf("[line %d] Error: invalid operation %s%s (operand must be numeric cannot be %s)", operator.Line, operator.Lexeme, repr(operand, make(map[interface{}]bool)), typeName(operand))
+		return errors.New(errStr)
+	case lexer.TokenTilde:
+		if isInteger(operand) {
+			return complement(operand)
+		}
+		errStr := fmt.Sprintf("[line %d] Error: invalid operation %s%s (operand must be an integer cannot be %s)", operator.Line, operator.Lexeme, repr(operand, make(map[interface{}]bool)), typeName(operand))
 		return errors.New(errStr)
 	case lexer.TokenBang:
 		return !isTruthy(operand)
@@ -108,6 +196,9 @@ func (i *Interpreter) VisitGrouping(gr *Grouping[any, interface{}]) interface{}
 }
 func (i *Interpreter) VisitLiteral(lit *Literal[any, interface{}]) interface{} {
 	switch {
+	case lit.Type == "integer":
+		value, _ := lexer.ParseInteger(lit.Token.Lexeme)
+		return normalizeInt(value)
 	case lit.Type == "number":
 		strVal := lit.Value.(string)
 		parsedFloatNum, _ := strconv.ParseFloat(strVal, 64)
@@ -115,6 +206,9 @@ func (i *Interpreter) VisitLiteral(lit *Literal[any, interface{}]) interface{} {
 	case lit.Type == "string":
 		strVal := lit.Value.(string)
 		return strVal
+	case lit.Token.Type == lexer.TokenNil:
+		// the parser spells nil as a string, which must not equal the string "nil"
+		return nil
 	default:
 		return lit.Value
 	}
@@ -148,8 +242,539 @@ func (i *Interpreter) VisitTernary(t *Ternary[any, interface{}]) interface{} {
 	}
 	return ifFalse
 }
+func (i *Interpreter) VisitInterpolation(in *Interpolation[any, interface{}]) interface{} {
+	sb := strings.Builder{}
+	for _, part := range in.Parts {
+		value := i.evaluate(part)
+		if err, ok := value.(error); ok {
+			return err
+		}
+		sb.WriteString(stringify(value))
+	}
+	return sb.String()
+}
+func (i *Interpreter) VisitList(li *List[any, interface{}]) interface{} {
+	elements, err := i.evaluateElements(li.Elements)
+	if err != nil {
+		return err
+	}
+	return &ListValue{
+		Elements: elements,
+	}
+}
+func (i *Interpreter) VisitIndex(ix *Index[any, interface{}]) interface{} {
+	object := i.evaluate(ix.Object)
+	if err, ok := object.(error); ok {
+		return err
+	}
+	if _, ok := object.(shortCircuit); ok {
+		return object
+	}
+	index := i.evaluate(ix.Index)
+	if err, ok := index.(error); ok {
+		return err
+	}
+	return i.getIndex(ix.Bracket, object, index)
+}
+func (i *Interpreter) VisitSlice(sl *Slice[any, interface{}]) interface{} {
+	object := i.evaluate(sl.Object)
+	if err, ok := object.(error); ok {
+		return err
+	}
+	if _, ok := object.(shortCircuit); ok {
+		return object
+	}
+	var bounds [2]interface{}
+	for idx, bound := range []Expr[any, interface{}]{sl.Start, sl.End} {
+		if bound == nil {
+			continue
+		}
+		bounds[idx] = i.evaluate(bound)
+		if err, ok := bounds[idx].(error); ok {
+			return err
+		}
+	}
+	list, ok := object.(*ListValue)
+	if !ok {
+		errStr := fmt.Sprintf("[line %d] Error: cannot slice %s", sl.Bracket.Line, typeName(object))
+		return errors.New(errStr)
+	}
+	start, end, err := sliceBounds(bounds[0], bounds[1], len(list.Elements))
+	if err != nil {
+		errStr := fmt.Sprintf("[line %d] Error: %s", sl.Bracket.Line, err.Error())
+		return errors.New(errStr)
+	}
+	// a slice is a new list
+	elements := make([]interface{}, end-start)
+	copy(elements, list.Elements[start:end])
+	return &ListValue{
+		Elements: elements,
+	}
+}
+func (i *Interpreter) VisitSetIndex(si *SetIndex[any, interface{}]) interface{} {
+	// the target's object and index are evaluated exactly once
+	object := i.evaluate(si.Target.Object)
+	if err, ok := object.(error); ok {
+		return err
+	}
+	index := i.evaluate(si.Target.Index)
+	if err, ok := index.(error); ok {
+		return err
+	}
+	if si.Operator.Type == lexer.TokenEqual {
+		value := i.evaluate(si.Value)
+		if err, ok := value.(error); ok {
+			return err
+		}
+		return i.setIndex(si.Target.Bracket, object, index, value)
+	}
+	current := i.getIndex(si.Target.Bracket, object, index)
+	if err, ok := current.(error); ok {
+		return err
+	}
+	var operand interface{} = int64(1)
+	if si.Value != nil {
+		operand = i.evaluate(si.Value)
+		if err, ok := operand.(error); ok {
+			return err
+		}
+	}
+	updated := i.binary(arithmeticOperator(si.Operator), current, operand)
+	if err, ok := updated.(error); ok {
+		return err
+	}
+	if err, ok := i.setIndex(si.Target.Bracket, object, index, updated).(error); ok {
+		return err
+	}
+	if si.Value == nil && !si.Prefix {
+		// postfix increments evaluate to the value before the update
+		return current
+	}
+	return updated
+}
+func (i *Interpreter) VisitGet(g *Get[any, interface{}]) interface{} {
+	object := i.evaluate(g.Object)
+	if err, ok := object.(error); ok {
+		return err
+	}
+	if _, ok := object.(shortCircuit); ok {
+		return object
+	}
+	if g.Optional && object == nil {
+		return shortCircuit{}
+	}
+	switch obj := object.(type) {
+	case *ListValue:
+		if method, ok := listMethod(obj, g.Name.Lexeme); ok {
+			return method
+		}
+	case *MapValue:
+		if method, ok := mapMethod(obj, g.Name.Lexeme); ok {
+			return method
+		}
+	}
+	errStr := fmt.Sprintf("[line %d] Error: %s has no property %s", g.Name.Line, typeName(object), g.Name.Lexeme)
+	return errors.New(errStr)
+}
+func (i *Interpreter) VisitCall(c *Call[any, interface{}]) interface{} {
+	callee := i.evaluate(c.Callee)
+	if err, ok := callee.(error); ok {
+		return err
+	}
+	if _, ok := callee.(shortCircuit); ok {
+		return callee
+	}
+	args, err := i.evaluateElements(c.Arguments)
+	if err != nil {
+		return err
+	}
+	function, ok := callee.(Callable)
+	if !ok {
+		errStr := fmt.Sprintf("[line %d] Error: Can only call functions and methods.", c.Paren.Line)
+		return errors.New(errStr)
+	}
+	if min, max := function.Arity(); len(args) < min || (max >= 0 && len(args) > max) {
+		errStr := fmt.Sprintf("[line %d] Error: %s expected %s but got %d.", c.Paren.Line, callableName(function), arityText(min, max), len(args))
+		return errors.New(errStr)
+	}
+	result, err := function.Call(i, args)
+	if err != nil {
+		errStr := fmt.Sprintf("[line %d] Error: %s", c.Paren.Line, err.Error())
+		return errors.New(errStr)
+	}
+	return result
+}
+func (i *Interpreter) VisitMatch(m *Match[any, interface{}]) interface{} {
+	subject := i.evaluate(m.Subject)
+	if err, ok := subject.(error); ok {
+		return err
+	}
+	previous := i.env
+	defer func() {
+		i.env = previous
+	}()
+	for _, arm := range m.Arms {
+		// every arm binds its names in a fresh environment
+		i.env = NewEnvironment(previous)
+		matched, err := i.matchPattern(arm.Pattern, subject)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+		if arm.Guard != nil {
+			guard := i.evaluate(arm.Guard)
+			if err, ok := guard.(error); ok {
+				return err
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+		return i.evaluate(arm.Body)
+	}
+	errStr := fmt.Sprintf("[line %d] Error: no match arm matches %s", m.Keyword.Line, repr(subject, make(map[interface{}]bool)))
+	return errors.New(errStr)
+}
+
+// matchPattern matches value against pattern, defining the names that the
+// pattern binds in the current environment
+func (i *Interpreter) matchPattern(pattern Pattern[any, interface{}], value interface{}) (bool, error) {
+	switch p := pattern.(type) {
+	case *LiteralPattern[any, interface{}]:
+		literal := i.evaluate(p.Value)
+		if err, ok := literal.(error); ok {
+			return false, err
+		}
+		equal, err := isEqual(literal, value)
+		// values of different types simply do not match
+		return err == nil && equal, nil
+	case *WildcardPattern[any, interface{}]:
+		return true, nil
+	case *BindingPattern[any, interface{}]:
+		i.env.Define(p.Name.Lexeme, value)
+		return true, nil
+	case *ListPattern[any, interface{}]:
+		list, ok := value.(*ListValue)
+		if !ok || len(list.Elements) < len(p.Elements) || (p.Rest == nil && len(list.Elements) != len(p.Elements)) {
+			return false, nil
+		}
+		for idx, element := range p.Elements {
+			if matched, err := i.matchPattern(element, list.Elements[idx]); err != nil || !matched {
+				return false, err
+			}
+		}
+		if p.Rest != nil && p.Rest.Lexeme != "_" {
+			rest := make([]interface{}, len(list.Elements)-len(p.Elements))
+			copy(rest, list.Elements[len(p.Elements):])
+			i.env.Define(p.Rest.Lexeme, &ListValue{
+				Elements: rest,
+			})
+		}
+		return true, nil
+	case *MapPattern[any, interface{}]:
+		m, ok := value.(*MapValue)
+		if !ok {
+			return false, nil
+		}
+		keys, err := i.patternKeys(p)
+		if err != nil {
+			return false, err
+		}
+		for idx, key := range keys {
+			entry, found, err := m.Get(key)
+			if err != nil || !found {
+				return false, nil
+			}
+			if matched, err := i.matchPattern(p.Values[idx], entry); err != nil || !matched {
+				return false, err
+			}
+		}
+		if p.Rest != nil && p.Rest.Lexeme != "_" {
+			i.env.Define(p.Rest.Lexeme, restOfMap(m, keys))
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// destructure binds the names of a destructured parameter to the parts of
+// value. Unlike matching, a value of the wrong shape is an error.
+func (i *Interpreter) destructure(pattern Pattern[any, interface{}], value interface{}) error {
+	switch p := pattern.(type) {
+	case *BindingPattern[any, interface{}]:
+		i.env.Define(p.Name.Lexeme, value)
+	case *ListPattern[any, interface{}]:
+		list, ok := value.(*ListValue)
+		if !ok {
+			return fmt.Errorf("[line %d] Error: cannot destructure %s as a list", p.Bracket.Line, typeName(value))
+		}
+		required := 0
+		for idx, element := range p.Elements {
+			if _, ok := element.(*DefaultPattern[any, interface{}]); !ok {
+				required = idx + 1
+			}
+		}
+		switch {
+		case p.Rest == nil && len(list.Elements) > len(p.Elements):
+			return fmt.Errorf("[line %d] Error: cannot destructure a list of %d elements into %d", p.Bracket.Line, len(list.Elements), len(p.Elements))
+		case len(list.Elements) < required:
+			return fmt.Errorf("[line %d] Error: cannot destructure a list of %d elements, need at least %d", p.Bracket.Line, len(list.Elements), required)
+		}
+		for idx, element := range p.Elements {
+			var err error
+			if idx < len(list.Elements) {
+				err = i.destructureElement(element, list.Elements[idx])
+			} else {
+				err = i.destructureDefault(element)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if p.Rest != nil && p.Rest.Lexeme != "_" {
+			rest := make([]interface{}, 0)
+			if len(list.Elements) > len(p.Elements) {
+				rest = append(rest, list.Elements[len(p.Elements):]...)
+			}
+			i.env.Define(p.Rest.Lexeme, &ListValue{
+				Elements: rest,
+			})
+		}
+	case *MapPattern[any, interface{}]:
+		m, ok := value.(*MapValue)
+		if !ok {
+			return fmt.Errorf("[line %d] Error: cannot destructure %s as a map", p.Brace.Line, typeName(value))
+		}
+		keys, err := i.patternKeys(p)
+		if err != nil {
+			return err
+		}
+		for idx, key := range keys {
+			entry, found, err := m.Get(key)
+			switch {
+			case err != nil:
+				return fmt.Errorf("[line %d] Error: %s", p.Brace.Line, err.Error())
+			case found:
+				err = i.destructureElement(p.Values[idx], entry)
+			default:
+				if _, ok := p.Values[idx].(*DefaultPattern[any, interface{}]); !ok {
+					return fmt.Errorf("[line %d] Error: cannot destructure map without key %s", p.Brace.Line, repr(key, make(map[interface{}]bool)))
+				}
+				err = i.destructureDefault(p.Values[idx])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if p.Rest != nil && p.Rest.Lexeme != "_" {
+			i.env.Define(p.Rest.Lexeme, restOfMap(m, keys))
+		}
+	}
+	return nil
+}
+
+// destructureElement destructures a value that is present, ignoring any default
+func (i *Interpreter) destructureElement(pattern Pattern[any, interface{}], value interface{}) error {
+	if def, ok := pattern.(*DefaultPattern[any, interface{}]); ok {
+		pattern = def.Pattern
+	}
+	return i.destructure(pattern, value)
+}
+
+// destructureDefault destructures the default value of a missing element
+func (i *Interpreter) destructureDefault(pattern Pattern[any, interface{}]) error {
+	def := pattern.(*DefaultPattern[any, interface{}])
+	value := i.evaluate(def.Default)
+	if err, ok := value.(error); ok {
+		return err
+	}
+	return i.destructure(def.Pattern, value)
+}
+
+// patternKeys evaluates the keys of a map pattern, shorthand entries being
+// keyed by the name they bind
+func (i *Interpreter) patternKeys(p *MapPattern[any, interface{}]) ([]interface{}, error) {
+	keys := make([]interface{}, 0, len(p.Keys))
+	for idx, keyExpr := range p.Keys {
+		if keyExpr == nil {
+			keys = append(keys, p.ShorthandName(idx))
+			continue
+		}
+		key := i.evaluate(keyExpr)
+		if err, ok := key.(error); ok {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// restOfMap copies the entries of m whose keys are not in keys
+func restOfMap(m *MapValue, keys []interface{}) *MapValue {
+	rest := NewMapValue()
+	for _, entry := range m.Entries() {
+		taken := false
+		for _, key := range keys {
+			if ok, err := isEqual(entry.Key, key); err == nil && ok {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			rest.Set(entry.Key, entry.Value)
+		}
+	}
+	return rest
+}
+
+// shortCircuit is passed up an optional chain from the "?." that found nil,
+// skipping the rest of the chain
+type shortCircuit struct{}
+
+func (i *Interpreter) VisitOptionalChain(oc *OptionalChain[any, interface{}]) interface{} {
+	value := i.evaluate(oc.Expression)
+	if _, ok := value.(shortCircuit); ok {
+		return nil
+	}
+	return value
+}
+func (i *Interpreter) VisitSpread(s *Spread[any, interface{}]) interface{} {
+	// spreads are expanded by evaluateElements, this only evaluates the list
+	return i.evaluate(s.Expression)
+}
+
+// evaluateElements evaluates the elements of a list or the arguments of a call
+// in order, expanding spread lists in place
+func (i *Interpreter) evaluateElements(exprs []Expr[any, interface{}]) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(exprs))
+	for _, expr := range exprs {
+		spread, isSpread := expr.(*Spread[any, interface{}])
+		if isSpread {
+			expr = spread.Expression
+		}
+		value := i.evaluate(expr)
+		if err, ok := value.(error); ok {
+			return nil, err
+		}
+		if !isSpread {
+			values = append(values, value)
+			continue
+		}
+		list, ok := value.(*ListValue)
+		if !ok {
+			errStr := fmt.Sprintf("[line %d] Error: cannot spread %s", spread.Ellipsis.Line, typeName(value))
+			return nil, errors.New(errStr)
+		}
+		values = append(values, list.Elements...)
+	}
+	return values, nil
+}
+func (i *Interpreter) VisitMap(m *Map[any, interface{}]) interface{} {
+	value := NewMapValue()
+	for idx := range m.Keys {
+		key := i.evaluate(m.Keys[idx])
+		if err, ok := key.(error); ok {
+			return err
+		}
+		val := i.evaluate(m.Values[idx])
+		if err, ok := val.(error); ok {
+			return err
+		}
+		if err := value.Set(key, val); err != nil {
+			errStr := fmt.Sprintf("[line %d] Error: %s", m.Brace.Line, err.Error())
+			return errors.New(errStr)
+		}
+	}
+	return value
+}
+func (i *Interpreter) VisitVariable(v *Variable[any, interface{}]) interface{} {
+	value, err := i.env.Get(v.Name)
+	if err != nil {
+		errStr := fmt.Sprintf("[line %d] Error: %s", v.Name.Line, err.Error())
+		return errors.New(errStr)
+	}
+	return value
+}
+func (i *Interpreter) VisitLambda(l *Lambda[any, interface{}]) interface{} {
+	return &Function{
+		Declaration: l,
+		Closure:     i.env,
+	}
+}
+func (i *Interpreter) getIndex(bracket lexer.Token, object, index interface{}) interface{} {
+	if m, ok := object.(*MapValue); ok {
+		value, found, err := m.Get(index)
+		if err == nil && !found {
+			err = fmt.Errorf("undefined key %s", repr(index, make(map[interface{}]bool)))
+		}
+		if err != nil {
+			errStr := fmt.Sprintf("[line %d] Error: %s", bracket.Line, err.Error())
+			return errors.New(errStr)
+		}
+		return value
+	}
+	list, ok := object.(*ListValue)
+	if !ok {
+		errStr := fmt.Sprintf("[line %d] Error: cannot index %s", bracket.Line, typeName(object))
+		return errors.New(errStr)
+	}
+	position, err := listPosition(index, len(list.Elements))
+	if err != nil {
+		errStr := fmt.Sprintf("[line %d] Error: %s", bracket.Line, err.Error())
+		return errors.New(errStr)
+	}
+	return list.Elements[position]
+}
+func (i *Interpreter) setIndex(bracket lexer.Token, object, index, value interface{}) interface{} {
+	if m, ok := object.(*MapValue); ok {
+		if err := m.Set(index, value); err != nil {
+			errStr := fmt.Sprintf("[line %d] Error: %s", bracket.Line, err.Error())
+			return errors.New(errStr)
+		}
+		return value
+	}
+	list, ok := object.(*ListValue)
+	if !ok {
+		errStr := fmt.Sprintf("[line %d] Error: cannot index %s", bracket.Line, typeName(object))
+		return errors.New(errStr)
+	}
+	position, err := listPosition(index, len(list.Elements))
+	if err != nil {
+		errStr := fmt.Sprintf("[line %d] Error: %s", bracket.Line, err.Error())
+		return errors.New(errStr)
+	}
+	list.Elements[position] = value
+	return value
+}
+
+// arithmeticOperator maps a compound assignment or increment operator to the
+// binary operator it applies
+func arithmeticOperator(operator lexer.Token) lexer.Token {
+	binaryOperators := map[lexer.TokenType]lexer.TokenType{
+		lexer.TokenPlusEqual:     lexer.TokenPlus,
+		lexer.TokenMinusEqual:    lexer.TokenMinus,
+		lexer.TokenStarEqual:     lexer.TokenStar,
+		lexer.TokenSlashEqual:    lexer.TokenSlash,
+		lexer.TokenStarStarEqual: lexer.TokenStarStar,
+		lexer.TokenPlusPlus:      lexer.TokenPlus,
+		lexer.TokenMinusMinus:    lexer.TokenMinus,
+	}
+	binaryOperator := operator
+	binaryOperator.Type = binaryOperators[operator.Type]
+	binaryOperator.Lexeme = strings.TrimSuffix(operator.Lexeme, "=")
+	if operator.Type == lexer.TokenPlusPlus || operator.Type == lexer.TokenMinusMinus {
+		binaryOperator.Lexeme = operator.Lexeme[:1]
+	}
+	return binaryOperator
+}
 func (i *Interpreter) evaluate(expr Expr[any, interface{}]) interface{} {
-	return expr.Accept(i)
+	value := expr.Accept(i)
+	if i.trace != nil {
+		i.trace(expr, value)
+	}
+	return value
 }
 func (i *Interpreter) Interpret(expr Expr[any, interface{}]) interface{} {
 	v := i.evaluate(expr)
@@ -165,18 +790,22 @@ func stringify(val interface{}) string {
 	switch v := val.(type) {
 	case string:
 		return v
-	case nil:
+	case nil, shortCircuit:
 		return "nil"
-	case float64:
-		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float64, int64, *big.Int:
+		return formatNumber(v)
+	case *ListValue, *MapValue:
+		return repr(v, make(map[interface{}]bool))
+	case *NativeFunction:
+		return "<native fn " + v.Name + ">"
+	case *Function:
+		return "<fn>"
 	default:
 		return fmt.Sprintf("%v", v)
 	}
 }
 func checkNumberOperands(op1, op2 interface{}) bool {
-	_, ok1 := op1.(float64)
-	_, ok2 := op2.(float64)
-	return ok1 && ok2
+	return isNumber(op1) && isNumber(op2)
 }
 
 func checkStringOperands(op1, op2 interface{}) bool {
@@ -189,7 +818,72 @@ func checkBooleanOperands(op1, op2 interface{}) bool {
 	_, ok2 := op2.(bool)
 	return ok1 && ok2
 }
+func checkListOperands(op1, op2 interface{}) bool {
+	_, ok1 := op1.(*ListValue)
+	_, ok2 := op2.(*ListValue)
+	return ok1 && ok2
+}
+func listsEqual(l1, l2 *ListValue, seen map[[2]interface{}]bool) (bool, error) {
+	if l1 == l2 {
+		return true, nil
+	}
+	if len(l1.Elements) != len(l2.Elements) {
+		return false, nil
+	}
+	// lists that contain themselves are equal if nothing else tells them apart
+	pair := [2]interface{}{l1, l2}
+	if seen[pair] {
+		return true, nil
+	}
+	seen[pair] = true
+	defer delete(seen, pair)
+	for idx := range l1.Elements {
+		ok, err := equal(l1.Elements[idx], l2.Elements[idx], seen)
+		if err != nil || !ok {
+			// elements of different types are simply not equal
+			return false, nil
+		}
+	}
+	return true, nil
+}
+func checkMapOperands(op1, op2 interface{}) bool {
+	_, ok1 := op1.(*MapValue)
+	_, ok2 := op2.(*MapValue)
+	return ok1 && ok2
+}
+
+// mapsEqual compares the entries of two maps regardless of their order
+func mapsEqual(m1, m2 *MapValue, seen map[[2]interface{}]bool) (bool, error) {
+	if m1 == m2 {
+		return true, nil
+	}
+	if m1.Len() != m2.Len() {
+		return false, nil
+	}
+	pair := [2]interface{}{m1, m2}
+	if seen[pair] {
+		return true, nil
+	}
+	seen[pair] = true
+	defer delete(seen, pair)
+	for _, entry := range m1.Entries() {
+		value, found, _ := m2.Get(entry.Key)
+		if !found {
+			return false, nil
+		}
+		if ok, err := equal(entry.Value, value, seen); err != nil || !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
 func isEqual(op1, op2 interface{}) (bool, error) {
+	return equal(op1, op2, make(map[[2]interface{}]bool))
+}
+
+// equal compares two values, seen holding the pairs of lists and maps being
+// compared further up so that containers which contain themselves end
+func equal(op1, op2 interface{}, seen map[[2]interface{}]bool) (bool, error) {
 	switch {
 	case op1 == nil && op2 == nil:
 		return true, nil
@@ -198,9 +892,13 @@ func isEqual(op1, op2 interface{}) (bool, error) {
 	case checkBooleanOperands(op1, op2):
 		return op1.(bool) == op2.(bool), nil
 	case checkNumberOperands(op1, op2):
-		return op1.(float64) == op2.(float64), nil
+		return compareNumbers(lexer.TokenEqualEqual, op1, op2), nil
 	case checkStringOperands(op1, op2):
 		return op1.(string) == op2.(string), nil
+	case checkListOperands(op1, op2):
+		return listsEqual(op1.(*ListValue), op2.(*ListValue), seen)
+	case checkMapOperands(op1, op2):
+		return mapsEqual(op1.(*MapValue), op2.(*MapValue), seen)
 	default:
 		return false, errors.New("type mismatch")
 	}
@@ -0,0 +1,187 @@
+package exprVisitors_test
+
+import (
+	"testing"
+
+	"github/goInterpreter/parser/exprVisitors"
+)
+
+func TestInterpret(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		numeric exprVisitors.NumericPolicy
+		// the printed result, or the error message
+		want string
+	}{
+		// integers and their promotion to big integers
+		{"int64 overflow on +", "9223372036854775807 + 1", exprVisitors.NumericIEEE, "9223372036854775808"},
+		{"int64 overflow on -", "-9223372036854775808 - 1", exprVisitors.NumericIEEE, "-9223372036854775809"},
+		{"int64 overflow on *", "9223372036854775807 * -2", exprVisitors.NumericIEEE, "-18446744073709551614"},
+		{"big power", "2 ** 64", exprVisitors.NumericIEEE, "18446744073709551616"},
+		{"big results shrink back", "2 ** 64 - 2 ** 64 + 1", exprVisitors.NumericIEEE, "1"},
+		{"big shift", "1 << 70", exprVisitors.NumericIEEE, "1180591620717411303424"},
+		{"integer division is exact", "1 / 2", exprVisitors.NumericIEEE, "0.5"},
+		{"floor division", "7 ~/ 2", exprVisitors.NumericIEEE, "3"},
+		{"modulo takes the sign of the divisor", "-7 % 3", exprVisitors.NumericIEEE, "2"},
+		{"float modulo", "-7.5 % 2", exprVisitors.NumericIEEE, "0.5"},
+		{"mixed operands are floats", "1.0 + 1", exprVisitors.NumericIEEE, "2.0"},
+		{"bitwise or", "0x10 | 0b1", exprVisitors.NumericIEEE, "17"},
+		{"bitwise and on a float", "1.5 & 1", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation 1.5 & 1 (operator & is only defined on integers)"},
+		{"negative exponent", "2 ** -1", exprVisitors.NumericIEEE, "0.5"},
+		{"power too large", "2 ** 100000000000", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation 2 ** 100000000000 (exponent 100000000000 too large)"},
+		{"huge power of one", "(-1) ** 100000000001", exprVisitors.NumericIEEE, "-1"},
+		{"integer division by zero", "1 ~/ 0", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation 1 ~/ 0 (integer division by zero)"},
+		// numeric policies
+		{"ieee division by zero", "1 / 0", exprVisitors.NumericIEEE, "Infinity"},
+		{"ieee zero by zero", "0 / 0", exprVisitors.NumericIEEE, "NaN"},
+		{"strict division by zero", "1 / 0", exprVisitors.NumericStrict, "[line 1] Error: invalid operation 1 / 0 (division by zero)"},
+		{"strict zero to a negative power", "0 ** -1", exprVisitors.NumericStrict, "[line 1] Error: invalid operation 0 ** -1 (division by zero)"},
+		{"strict root of a negative number", "(-8) ** 0.5", exprVisitors.NumericStrict, "[line 1] Error: invalid operation -8 ** 0.5 (result is not a finite real number)"},
+		{"strict float modulo by zero", "1.0 % 0", exprVisitors.NumericStrict, "[line 1] Error: invalid operation 1.0 % 0 (division by zero)"},
+		{"strict float overflow", "10.0 ** 400", exprVisitors.NumericStrict, "[line 1] Error: invalid operation 10.0 ** 400 (result is not a finite real number)"},
+		{"strict finite result", "2 ** -1", exprVisitors.NumericStrict, "0.5"},
+		// nil operands
+		{"nil plus", "nil + 1", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation nil + 1 (mismatched types nil and number)"},
+		{"nil comparison", "nil < 1", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation nil < 1 (mismatched types nil and number)"},
+		{"nil bitwise and", "nil & 1", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation nil & 1 (mismatched types nil and number)"},
+		{"nil on the right", "1 ~/ nil", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation 1 ~/ nil (mismatched types number and nil)"},
+		{"negated nil", "-nil", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation -nil (operand must be numeric cannot be nil)"},
+		{"complemented nil", "~nil", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation ~nil (operand must be an integer cannot be nil)"},
+		{"nil equality", "nil == nil", exprVisitors.NumericIEEE, "true"},
+		// operands in error messages
+		{"list operand", "[1] + 1", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation [1] + 1 (mismatched types list and number)"},
+		{"map operand", "{1: 2} < 1", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation {1: 2} < 1 (mismatched types map and number)"},
+		{"string operand", `1 + "a"`, exprVisitors.NumericIEEE, `[line 1] Error: invalid operation 1 + "a" (mismatched types number and string)`},
+		{"negated list", "-[1]", exprVisitors.NumericIEEE, "[line 1] Error: invalid operation -[1] (operand must be numeric cannot be list)"},
+		// match and destructuring
+		{"negative literal pattern", `match -1 { case -1 => "neg" case _ => "other" }`, exprVisitors.NumericIEEE, "neg"},
+		{"nested list pattern", "match [1, [2, 3]] { case [a, [b, c]] => a + b + c }", exprVisitors.NumericIEEE, "6"},
+		{"list pattern of the wrong length", `match [1, 2] { case [a] => a case _ => "no" }`, exprVisitors.NumericIEEE, "no"},
+		{"list pattern with rest", "match [1, 2, 3] { case [a, ...r] => r }", exprVisitors.NumericIEEE, "[2, 3]"},
+		{"map pattern", `match {"a": 1, "b": 2} { case {"a": x} => x }`, exprVisitors.NumericIEEE, "1"},
+		{"map pattern with rest", `match {"a": 1, "b": 2} { case {"a": x, ...r} => [x, r] }`, exprVisitors.NumericIEEE, `[1, {"b": 2}]`},
+		{"guard", `match 5 { case 1 => "one" case n if n > 3 => "big" case _ => "small" }`, exprVisitors.NumericIEEE, "big"},
+		{"parenthesised guard", `match 1 { case t if (t) => "yes" case _ => "no" }`, exprVisitors.NumericIEEE, "yes"},
+		{"lambda in a guard", `match 1 { case t if ((x) => x > 0)(t) => "yes" case _ => "no" }`, exprVisitors.NumericIEEE, "yes"},
+		{"lambda in a guard's list", `match 1 { case t if [(x) => x][0](t) => "yes" case _ => "no" }`, exprVisitors.NumericIEEE, "yes"},
+		{"no arm matches", `match 2 { case 1 => "one" }`, exprVisitors.NumericIEEE, "[line 1] Error: no match arm matches 2"},
+		{"destructured parameter with default", "(([a, b = 5]) => a + b)([1])", exprVisitors.NumericIEEE, "6"},
+		{"destructured map parameter", `(({x, "y": z}) => x + z)({"x": 1, "y": 2})`, exprVisitors.NumericIEEE, "3"},
+		{"destructuring the wrong type", "(([a]) => a)(1)", exprVisitors.NumericIEEE, "[line 1] Error: cannot destructure number as a list"},
+		// optional chaining and nil coalescing
+		{"coalesce nil", "nil ?? 1", exprVisitors.NumericIEEE, "1"},
+		{"coalesce keeps false", "false ?? 1", exprVisitors.NumericIEEE, "false"},
+		{"coalesce chain", "1 ?? 2 ?? 3", exprVisitors.NumericIEEE, "1"},
+		{"optional call on nil", "nil?.len()", exprVisitors.NumericIEEE, "nil"},
+		{"optional call on a list", "[1, 2]?.len()", exprVisitors.NumericIEEE, "2"},
+		{"optional chain short-circuits", "nil?.x.y[0]", exprVisitors.NumericIEEE, "nil"},
+		{"grouping ends the chain", "(nil?.x).y", exprVisitors.NumericIEEE, "[line 1] Error: nil has no property y"},
+		// lists
+		{"list literal", `[1, [2, "a"],]`, exprVisitors.NumericIEEE, `[1, [2, "a"]]`},
+		{"index", "[1, 2, 3][0]", exprVisitors.NumericIEEE, "1"},
+		{"negative index", "[1, 2, 3][-1]", exprVisitors.NumericIEEE, "3"},
+		{"index past the end", "[1, 2][2]", exprVisitors.NumericIEEE, "[line 1] Error: list index 2 out of range for length 2"},
+		{"negative index past the start", "[1, 2][-3]", exprVisitors.NumericIEEE, "[line 1] Error: list index -3 out of range for length 2"},
+		{"big index", "[1][9223372036854775808]", exprVisitors.NumericIEEE, "[line 1] Error: list index 9223372036854775808 out of range"},
+		{"float index", "[1, 2][1.0]", exprVisitors.NumericIEEE, "[line 1] Error: list indices must be integers, not 1.0"},
+		{"string index", `[1, 2]["a"]`, exprVisitors.NumericIEEE, "[line 1] Error: list indices must be integers, not string"},
+		{"slice from", "[1, 2, 3][1:]", exprVisitors.NumericIEEE, "[2, 3]"},
+		{"slice to a negative bound", "[1, 2, 3][:-1]", exprVisitors.NumericIEEE, "[1, 2]"},
+		{"slice from a negative bound", "[1, 2, 3][-2:]", exprVisitors.NumericIEEE, "[2, 3]"},
+		{"slice to the end", "[1, 2][0:2]", exprVisitors.NumericIEEE, "[1, 2]"},
+		{"slice bound past the end", "[1, 2][0:3]", exprVisitors.NumericIEEE, "[line 1] Error: slice bound 3 out of range for length 2"},
+		{"crossed slice bounds", "[1, 2, 3][2:1]", exprVisitors.NumericIEEE, "[line 1] Error: slice bounds out of range [2:1]"},
+		{"float slice bound", "[1, 2][1.5:]", exprVisitors.NumericIEEE, "[line 1] Error: slice bounds must be integers, not 1.5"},
+		{"push", "[1, 2].push(3)", exprVisitors.NumericIEEE, "nil"},
+		{"pop", "[1, 2].pop()", exprVisitors.NumericIEEE, "2"},
+		{"pop from an empty list", "[].pop()", exprVisitors.NumericIEEE, "[line 1] Error: pop from empty list"},
+		{"insert", "[1, 2].insert(2, 3)", exprVisitors.NumericIEEE, "nil"},
+		{"insert past the end", "[1, 2].insert(3, 0)", exprVisitors.NumericIEEE, "[line 1] Error: list index 3 out of range for length 3"},
+		{"remove", "[1, 2].remove(-1)", exprVisitors.NumericIEEE, "2"},
+		{"remove past the end", "[1, 2].remove(2)", exprVisitors.NumericIEEE, "[line 1] Error: list index 2 out of range for length 2"},
+		{"contains", "[1, [2]].contains([2])", exprVisitors.NumericIEEE, "true"},
+		{"does not contain", "[1, 2].contains(3)", exprVisitors.NumericIEEE, "false"},
+		{"len", "[1, 2].len()", exprVisitors.NumericIEEE, "2"},
+		{"unknown method", "[1, 2].nope()", exprVisitors.NumericIEEE, "[line 1] Error: list has no property nope"},
+		{"method without its argument", "[1, 2].push()", exprVisitors.NumericIEEE, "[line 1] Error: push() expected 1 argument but got 0."},
+		{"assignment through an index", "[1, 2][0] = 5", exprVisitors.NumericIEEE, "5"},
+		{"compound assignment through an index", "[1, 2][-1] += 5", exprVisitors.NumericIEEE, "7"},
+		{"assignment past the end", "[1, 2][2] = 5", exprVisitors.NumericIEEE, "[line 1] Error: list index 2 out of range for length 2"},
+		{"equal lists", "[1, [2]] == [1, [2]]", exprVisitors.NumericIEEE, "true"},
+		{"push changes the list", "((xs) => (xs.push(3), xs))([1, 2])", exprVisitors.NumericIEEE, "[1, 2, 3]"},
+		{"pop changes the list", "((xs) => (xs.pop(), xs))([1, 2])", exprVisitors.NumericIEEE, "[1]"},
+		{"insert changes the list", "((xs) => (xs.insert(0, 0), xs))([1, 2])", exprVisitors.NumericIEEE, "[0, 1, 2]"},
+		{"remove changes the list", "((xs) => (xs.remove(0), xs))([1, 2])", exprVisitors.NumericIEEE, "[2]"},
+		{"assignment through an index changes the list", "((xs) => (xs[-1] = 5, xs))([1, 2])", exprVisitors.NumericIEEE, "[1, 5]"},
+		// maps
+		{"map literal", `{"a": 1, 2: [3],}`, exprVisitors.NumericIEEE, `{"a": 1, 2: [3]}`},
+		{"key lookup", `{"a": 1}["a"]`, exprVisitors.NumericIEEE, "1"},
+		{"missing key", `{"a": 1}["b"]`, exprVisitors.NumericIEEE, `[line 1] Error: undefined key "b"`},
+		{"equal numbers are the same key", `{1: "x"}[1.0]`, exprVisitors.NumericIEEE, "x"},
+		{"duplicate keys keep their first position", `{"b": 1, "a": 2, "b": 3}`, exprVisitors.NumericIEEE, `{"b": 3, "a": 2}`},
+		{"keys in insertion order", `{"b": 1, "a": 2}.keys()`, exprVisitors.NumericIEEE, `["b", "a"]`},
+		{"values in insertion order", `{"b": 1, "a": 2}.values()`, exprVisitors.NumericIEEE, "[1, 2]"},
+		{"has", `{"a": 1}.has("a")`, exprVisitors.NumericIEEE, "true"},
+		{"does not have", `{"a": 1}.has("b")`, exprVisitors.NumericIEEE, "false"},
+		{"delete", `{"a": 1}.delete("a")`, exprVisitors.NumericIEEE, "true"},
+		{"delete a missing key", `{"a": 1}.delete("b")`, exprVisitors.NumericIEEE, "false"},
+		{"map len", `{"a": 1, "b": 2}.len()`, exprVisitors.NumericIEEE, "2"},
+		{"unknown map method", `{"a": 1}.nope()`, exprVisitors.NumericIEEE, "[line 1] Error: map has no property nope"},
+		{"assignment to a new key", `{"a": 1}["b"] = 2`, exprVisitors.NumericIEEE, "2"},
+		{"compound assignment to a key", `{"a": 1}["a"] += 2`, exprVisitors.NumericIEEE, "3"},
+		{"unhashable key", "{[1]: 2}", exprVisitors.NumericIEEE, "[line 1] Error: unhashable map key of type list"},
+		{"unhashable lookup", `{"a": 1}[[1]]`, exprVisitors.NumericIEEE, "[line 1] Error: unhashable map key of type list"},
+		{"unhashable argument", `{"a": 1}.has([1])`, exprVisitors.NumericIEEE, "[line 1] Error: unhashable map key of type list"},
+		{"NaN key", "{0 / 0: 1}", exprVisitors.NumericIEEE, "[line 1] Error: NaN is not a valid map key"},
+		{"NaN lookup", "{1: 2}[0 / 0]", exprVisitors.NumericIEEE, "[line 1] Error: NaN is not a valid map key"},
+		{"equal maps", `{"a": [1]} == {"a": [1]}`, exprVisitors.NumericIEEE, "true"},
+		{"assignment adds a key at the end", `((m) => (m["a"] = 3, m["c"] = 4, m))({"a": 1, "b": 2})`, exprVisitors.NumericIEEE, `{"a": 3, "b": 2, "c": 4}`},
+		{"delete changes the map", `((m) => (m.delete("a"), m))({"a": 1, "b": 2})`, exprVisitors.NumericIEEE, `{"b": 2}`},
+		{"a deleted key comes back at the end", `((m) => (m.delete("a"), m["a"] = 3, m))({"a": 1, "b": 2})`, exprVisitors.NumericIEEE, `{"b": 2, "a": 3}`},
+		{"lists that contain themselves", "((a, b) => (a.push(a), b.push(b), a == b))([], [])", exprVisitors.NumericIEEE, "true"},
+		{"different lists that contain themselves", "((a, b) => (a.push(a), a.push(1), b.push(b), b.push(2), a == b))([], [])", exprVisitors.NumericIEEE, "false"},
+		{"maps that contain themselves", `((a, b) => (a["s"] = a, b["s"] = b, a == b))({}, {})`, exprVisitors.NumericIEEE, "true"},
+		{"different maps that contain themselves", `((a, b) => (a["s"] = a, a["n"] = 1, b["s"] = b, b["n"] = 2, a == b))({}, {})`, exprVisitors.NumericIEEE, "false"},
+		// lambdas
+		{"call", "((x) => x + 1)(1)", exprVisitors.NumericIEEE, "2"},
+		{"no parameters", "(() => 1)()", exprVisitors.NumericIEEE, "1"},
+		{"two parameters", "((a, b) => a * b)(2, 3)", exprVisitors.NumericIEEE, "6"},
+		{"closure", "((x) => (y) => x + y)(1)(2)", exprVisitors.NumericIEEE, "3"},
+		{"lambda value", "(x) => x", exprVisitors.NumericIEEE, "<fn>"},
+		{"too few arguments", "((x) => x)()", exprVisitors.NumericIEEE, "[line 1] Error: lambda expected 1 argument but got 0."},
+		{"too many arguments", "((x) => x)(1, 2)", exprVisitors.NumericIEEE, "[line 1] Error: lambda expected 1 argument but got 2."},
+		{"undefined variable", "((x) => y)(1)", exprVisitors.NumericIEEE, "[line 1] Error: Undefined variable 'y'."},
+		{"calling a number", "(1)(2)", exprVisitors.NumericIEEE, "[line 1] Error: Can only call functions and methods."},
+		{"recursion", "((f) => f(f, 10))((f, n) => n == 0 ? 0 : n + f(f, n - 1))", exprVisitors.NumericIEEE, "55"},
+		{"unbounded recursion", "((f) => f(f))((f) => f(f))", exprVisitors.NumericIEEE, "[line 1] Error: maximum call depth exceeded"},
+		// default and rest parameters and spread arguments
+		{"default used", "((a, b = 2) => a + b)(1)", exprVisitors.NumericIEEE, "3"},
+		{"default overridden", "((a, b = 2) => a + b)(1, 5)", exprVisitors.NumericIEEE, "6"},
+		{"default sees earlier parameters", "((a, b = a * 2) => b)(3)", exprVisitors.NumericIEEE, "6"},
+		{"rest parameter", "((a, ...r) => r)(1, 2, 3)", exprVisitors.NumericIEEE, "[2, 3]"},
+		{"empty rest parameter", "((...r) => r)()", exprVisitors.NumericIEEE, "[]"},
+		{"spread arguments", "((a, b) => a + b)(...[1, 2])", exprVisitors.NumericIEEE, "3"},
+		{"spread among arguments", "((...r) => r)(0, ...[1, 2], 3)", exprVisitors.NumericIEEE, "[0, 1, 2, 3]"},
+		{"spread in a list", "[0, ...[1, 2], 3]", exprVisitors.NumericIEEE, "[0, 1, 2, 3]"},
+		{"spreading a number", "((a) => a)(...1)", exprVisitors.NumericIEEE, "[line 1] Error: cannot spread number"},
+		{"too few arguments for a default", "((a, b = 1) => a)()", exprVisitors.NumericIEEE, "[line 1] Error: lambda expected 1 to 2 arguments but got 0."},
+		{"too many arguments for a default", "((a, b = 1) => a)(1, 2, 3)", exprVisitors.NumericIEEE, "[line 1] Error: lambda expected 1 to 2 arguments but got 3."},
+		{"too few arguments for a rest parameter", "((a, ...r) => a)()", exprVisitors.NumericIEEE, "[line 1] Error: lambda expected at least 1 argument but got 0."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, _ := parse(t, tt.source, false)
+			i := exprVisitors.Interpreter{
+				Numeric: tt.numeric,
+			}
+			got := i.Interpret(expr)
+			if err, ok := got.(error); ok {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("%q evaluated to %q, want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package exprVisitors
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github/goInterpreter/lexer"
+)
+
+// Numbers are either float64 or integers. Integers are int64 and are promoted
+// to *big.Int when an operation overflows; a *big.Int that fits into an int64
+// is always normalized back, so equal integers share one representation.
+
+func isNumber(op interface{}) bool {
+	switch op.(type) {
+	case float64, int64, *big.Int:
+		return true
+	}
+	return false
+}
+
+func isInteger(op interface{}) bool {
+	switch op.(type) {
+	case int64, *big.Int:
+		return true
+	}
+	return false
+}
+
+func toFloat(op interface{}) float64 {
+	switch v := op.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(v).Float64()
+		return f
+	}
+	return math.NaN()
+}
+
+func toBig(op interface{}) *big.Int {
+	switch v := op.(type) {
+	case int64:
+		return big.NewInt(v)
+	case *big.Int:
+		return v
+	}
+	return nil
+}
+
+func normalizeInt(v *big.Int) interface{} {
+	if v.IsInt64() {
+		return v.Int64()
+	}
+	return v
+}
+
+// integerArithmetic applies an arithmetic operator to two integers. ok is false
+// when the result is not an integer and float arithmetic should be used instead.
+func integerArithmetic(operator lexer.TokenType, left, right interface{}) (result interface{}, ok bool, err error) {
+	a, aSmall := left.(int64)
+	b, bSmall := right.(int64)
+	if aSmall && bSmall {
+		switch operator {
+		case lexer.TokenPlus:
+			if sum := a + b; (sum > a) == (b > 0) {
+				return sum, true, nil
+			}
+		case lexer.TokenMinus:
+			if diff := a - b; (diff < a) == (b > 0) {
+				return diff, true, nil
+			}
+		case lexer.TokenStar:
+			if a == 0 || b == 0 {
+				return int64(0), true, nil
+			}
+			if prod := a * b; prod/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64) {
+				return prod, true, nil
+			}
+		}
+	}
+	x, y := toBig(left), toBig(right)
+	switch operator {
+	case lexer.TokenPlus:
+		return normalizeInt(new(big.Int).Add(x, y)), true, nil
+	case lexer.TokenMinus:
+		return normalizeInt(new(big.Int).Sub(x, y)), true, nil
+	case lexer.TokenStar:
+		return normalizeInt(new(big.Int).Mul(x, y)), true, nil
+	case lexer.TokenStarStar:
+		if y.Sign() < 0 {
+			return nil, false, nil
+		}
+		// only 0, 1 and -1 have powers of any exponent that stay small
+		if x.CmpAbs(big.NewInt(1)) > 0 && (!y.IsInt64() || y.Int64() > maxShift/int64(x.BitLen()-1)) {
+			return nil, false, errors.New("exponent " + y.String() + " too large")
+		}
+		return normalizeInt(new(big.Int).Exp(x, y, nil)), true, nil
+	}
+	return nil, false, nil
+}
+
+// compareNumbers applies a comparison operator, comparing integers exactly
+func compareNumbers(operator lexer.TokenType, left, right interface{}) bool {
+	if isInteger(left) && isInteger(right) {
+		cmp := toBig(left).Cmp(toBig(right))
+		switch operator {
+		case lexer.TokenGreater:
+			return cmp > 0
+		case lexer.TokenGreaterEqual:
+			return cmp >= 0
+		case lexer.TokenLess:
+			return cmp < 0
+		case lexer.TokenLessEqual:
+			return cmp <= 0
+		}
+		return cmp == 0
+	}
+	l, r := toFloat(left), toFloat(right)
+	switch operator {
+	case lexer.TokenGreater:
+		return l > r
+	case lexer.TokenGreaterEqual:
+		return l >= r
+	case lexer.TokenLess:
+		return l < r
+	case lexer.TokenLessEqual:
+		return l <= r
+	}
+	return l == r
+}
+
+func negate(op interface{}) interface{} {
+	switch v := op.(type) {
+	case float64:
+		return -v
+	case int64:
+		if v == math.MinInt64 {
+			return new(big.Int).Neg(big.NewInt(v))
+		}
+		return -v
+	case *big.Int:
+		return normalizeInt(new(big.Int).Neg(v))
+	}
+	return nil
+}
+
+// formatNumber prints integers without a fraction and floats always with one.
+// Non-finite floats print as Infinity, -Infinity and NaN.
+func formatNumber(op interface{}) string {
+	switch v := op.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case *big.Int:
+		return v.String()
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NaN"
+		case math.IsInf(v, 1):
+			return "Infinity"
+		case math.IsInf(v, -1):
+			return "-Infinity"
+		}
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	}
+	return ""
+}
+
+// floorDivision implements ~/ and %, rounding the quotient towards negative
+// infinity so that the remainder takes the sign of the divisor
+func floorDivision(operator lexer.TokenType, left, right interface{}) (interface{}, error) {
+	if !isInteger(left) || !isInteger(right) {
+		l, r := toFloat(left), toFloat(right)
+		if operator == lexer.TokenTildeSlash {
+			return math.Floor(l / r), nil
+		}
+		m := math.Mod(l, r)
+		if m != 0 && (m < 0) != (r < 0) {
+			m += r
+		}
+		return m, nil
+	}
+	x, y := toBig(left), toBig(right)
+	if y.Sign() == 0 {
+		return nil, errors.New("integer division by zero")
+	}
+	q, m := new(big.Int).QuoRem(x, y, new(big.Int))
+	if m.Sign() != 0 && (m.Sign() < 0) != (y.Sign() < 0) {
+		q.Sub(q, big.NewInt(1))
+		m.Add(m, y)
+	}
+	if operator == lexer.TokenTildeSlash {
+		return normalizeInt(q), nil
+	}
+	return normalizeInt(m), nil
+}
+
+// bitwise applies & | ^ << >> to two integers using two's complement semantics
+func bitwise(operator lexer.TokenType, left, right interface{}) (interface{}, error) {
+	x, y := toBig(left), toBig(right)
+	switch operator {
+	case lexer.TokenAmpersand:
+		return normalizeInt(new(big.Int).And(x, y)), nil
+	case lexer.TokenPipe:
+		return normalizeInt(new(big.Int).Or(x, y)), nil
+	case lexer.TokenCaret:
+		return normalizeInt(new(big.Int).Xor(x, y)), nil
+	}
+	if y.Sign() < 0 {
+		return nil, errors.New("negative shift count " + y.String())
+	}
+	if !y.IsInt64() || y.Int64() > maxShift {
+		return nil, errors.New("shift count " + y.String() + " too large")
+	}
+	if operator == lexer.TokenLessLess {
+		return normalizeInt(new(big.Int).Lsh(x, uint(y.Int64()))), nil
+	}
+	return normalizeInt(new(big.Int).Rsh(x, uint(y.Int64()))), nil
+}
+
+// shifting further than this would only exhaust memory. It also caps the
+// number of bits in the result of an integer **.
+const maxShift = 1 << 20
+
+func complement(op interface{}) interface{} {
+	return normalizeInt(new(big.Int).Not(toBig(op)))
+}
@@ -0,0 +1,122 @@
+package exprVisitors
+
+import (
+	"strings"
+
+	"github/goInterpreter/lexer"
+)
+
+// Pattern is the left-hand side of a match arm or a destructured parameter.
+// Patterns are not expressions: they are only ever matched against a value,
+// so they are handled by type switches rather than by visitors.
+type Pattern[T, V any] interface {
+	pattern()
+}
+
+// LiteralPattern matches values equal to Value, a literal or a negated number
+type LiteralPattern[T, V any] struct {
+	Value Expr[T, V]
+}
+
+// WildcardPattern "_" matches anything without binding it
+type WildcardPattern[T, V any] struct {
+	Token lexer.Token
+}
+
+// BindingPattern matches anything and binds it to Name
+type BindingPattern[T, V any] struct {
+	Name lexer.Token
+}
+
+// ListPattern matches lists element by element. Without Rest the list must
+// have exactly as many elements; with Rest ("...name") the remaining elements
+// are bound to the Rest name as a list, unless it is "_".
+type ListPattern[T, V any] struct {
+	Bracket  lexer.Token
+	Elements []Pattern[T, V]
+	Rest     *lexer.Token
+}
+
+// MapPattern matches maps that have every key in Keys, the values of which
+// match the pattern at the same position. A nil key is the shorthand "{name}"
+// for the key "name" bound to name. The other keys are ignored, or collected
+// into a map bound to the Rest name.
+type MapPattern[T, V any] struct {
+	Brace  lexer.Token
+	Keys   []Expr[T, V]
+	Values []Pattern[T, V]
+	Rest   *lexer.Token
+}
+
+// DefaultPattern gives a destructured element or entry a value for when it is
+// missing
+type DefaultPattern[T, V any] struct {
+	Pattern Pattern[T, V]
+	Default Expr[T, V]
+}
+
+func (*LiteralPattern[T, V]) pattern()  {}
+func (*WildcardPattern[T, V]) pattern() {}
+func (*BindingPattern[T, V]) pattern()  {}
+func (*ListPattern[T, V]) pattern()     {}
+func (*MapPattern[T, V]) pattern()      {}
+func (*DefaultPattern[T, V]) pattern()  {}
+
+// ShorthandName is the name that the shorthand entry at idx binds
+func (m *MapPattern[T, V]) ShorthandName(idx int) string {
+	switch value := m.Values[idx].(type) {
+	case *BindingPattern[T, V]:
+		return value.Name.Lexeme
+	case *DefaultPattern[T, V]:
+		if binding, ok := value.Pattern.(*BindingPattern[T, V]); ok {
+			return binding.Name.Lexeme
+		}
+	}
+	return ""
+}
+
+// CatchAll reports whether a pattern matches every value
+func CatchAll[T, V any](p Pattern[T, V]) bool {
+	switch p.(type) {
+	case *WildcardPattern[T, V], *BindingPattern[T, V]:
+		return true
+	}
+	return false
+}
+
+// patternString renders a pattern in source syntax, printing the literals with print
+func patternString[T, V any](p Pattern[T, V], print func(Expr[T, V]) string) string {
+	switch pt := p.(type) {
+	case *LiteralPattern[T, V]:
+		return print(pt.Value)
+	case *WildcardPattern[T, V]:
+		return "_"
+	case *BindingPattern[T, V]:
+		return pt.Name.Lexeme
+	case *ListPattern[T, V]:
+		elements := make([]string, 0, len(pt.Elements)+1)
+		for _, element := range pt.Elements {
+			elements = append(elements, patternString(element, print))
+		}
+		if pt.Rest != nil {
+			elements = append(elements, "..."+pt.Rest.Lexeme)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *MapPattern[T, V]:
+		entries := make([]string, 0, len(pt.Keys)+1)
+		for idx := range pt.Keys {
+			if pt.Keys[idx] == nil {
+				entries = append(entries, patternString(pt.Values[idx], print))
+				continue
+			}
+			entries = append(entries, print(pt.Keys[idx])+": "+patternString(pt.Values[idx], print))
+		}
+		if pt.Rest != nil {
+			entries = append(entries, "..."+pt.Rest.Lexeme)
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	case *DefaultPattern[T, V]:
+		return patternString(pt.Pattern, print) + " = " + print(pt.Default)
+	}
+	return ""
+}
@@ -0,0 +1,455 @@
+package exprVisitors
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ListValue is the runtime value of a list. Lists are shared by reference.
+type ListValue struct {
+	Elements []interface{}
+}
+
+// MapValue is the runtime value of a map. Maps are shared by reference and
+// remember the order in which their keys were first inserted.
+type MapValue struct {
+	entries map[mapKey]*mapEntry
+	order   []mapKey
+}
+
+type mapEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// mapKey is the hashed form of a key. Keys that are equal under isEqual, like
+// 1 and 1.0, hash to the same mapKey.
+type mapKey struct {
+	kind  string
+	value interface{}
+}
+
+func NewMapValue() *MapValue {
+	return &MapValue{
+		entries: make(map[mapKey]*mapEntry),
+	}
+}
+
+// hashKey restricts keys to nil, booleans, numbers and strings
+func hashKey(key interface{}) (mapKey, error) {
+	switch k := key.(type) {
+	case nil:
+		return mapKey{kind: "nil"}, nil
+	case bool:
+		return mapKey{kind: "bool", value: k}, nil
+	case string:
+		return mapKey{kind: "string", value: k}, nil
+	case int64:
+		return mapKey{kind: "number", value: k}, nil
+	case *big.Int:
+		// big integers never fit into an int64 so their text cannot collide
+		return mapKey{kind: "number", value: k.String()}, nil
+	case float64:
+		if math.IsNaN(k) {
+			return mapKey{}, fmt.Errorf("NaN is not a valid map key")
+		}
+		if math.IsInf(k, 0) || k != math.Trunc(k) {
+			return mapKey{kind: "number", value: k}, nil
+		}
+		// integral floats share the key of the equal integer
+		integer, _ := new(big.Float).SetFloat64(k).Int(nil)
+		return hashKey(normalizeInt(integer))
+	}
+	return mapKey{}, fmt.Errorf("unhashable map key of type %s", typeName(key))
+}
+
+func (m *MapValue) Get(key interface{}) (interface{}, bool, error) {
+	hash, err := hashKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	entry, ok := m.entries[hash]
+	if !ok {
+		return nil, false, nil
+	}
+	return entry.Value, true, nil
+}
+
+// Set keeps the position, and the original key, of an existing entry
+func (m *MapValue) Set(key, value interface{}) error {
+	hash, err := hashKey(key)
+	if err != nil {
+		return err
+	}
+	if entry, ok := m.entries[hash]; ok {
+		entry.Value = value
+		return nil
+	}
+	m.entries[hash] = &mapEntry{
+		Key:   key,
+		Value: value,
+	}
+	m.order = append(m.order, hash)
+	return nil
+}
+
+func (m *MapValue) Delete(key interface{}) (bool, error) {
+	hash, err := hashKey(key)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := m.entries[hash]; !ok {
+		return false, nil
+	}
+	delete(m.entries, hash)
+	m.order = slices.DeleteFunc(m.order, func(k mapKey) bool {
+		return k == hash
+	})
+	return true, nil
+}
+
+func (m *MapValue) Len() int {
+	return len(m.order)
+}
+
+// Entries lists the entries in insertion order
+func (m *MapValue) Entries() []*mapEntry {
+	entries := make([]*mapEntry, 0, len(m.order))
+	for _, hash := range m.order {
+		entries = append(entries, m.entries[hash])
+	}
+	return entries
+}
+
+// Callable is any value that can be called with parentheses. Arity returns the
+// least and the most arguments it accepts, max being -1 when there is no limit.
+type Callable interface {
+	Arity() (min, max int)
+	Call(i *Interpreter, args []interface{}) (interface{}, error)
+}
+
+// NativeFunction is a callable implemented in Go, such as a method of a list
+type NativeFunction struct {
+	Name     string
+	ArgCount int
+	Fn       func(args []interface{}) (interface{}, error)
+}
+
+func (nf *NativeFunction) Arity() (int, int) {
+	return nf.ArgCount, nf.ArgCount
+}
+
+func (nf *NativeFunction) Call(i *Interpreter, args []interface{}) (interface{}, error) {
+	return nf.Fn(args)
+}
+
+// Function is a lambda together with the environment it was created in
+type Function struct {
+	Declaration *Lambda[any, interface{}]
+	Closure     *Environment
+}
+
+func (fn *Function) Arity() (int, int) {
+	required := 0
+	for idx := range fn.Declaration.Params {
+		if fn.Declaration.Defaults[idx] == nil && !(fn.Declaration.Rest && idx == len(fn.Declaration.Params)-1) {
+			required++
+		}
+	}
+	if fn.Declaration.Rest {
+		return required, -1
+	}
+	return required, len(fn.Declaration.Params)
+}
+
+// maxCallDepth bounds the nesting of lambda calls so that runaway recursion
+// is a runtime error rather than an overflow of the Go stack
+const maxCallDepth = 10000
+
+// Call returns an error raised by the body as the result so that it reaches
+// the caller unchanged. Default values are evaluated on every call, after the
+// parameters before them have been bound.
+func (fn *Function) Call(i *Interpreter, args []interface{}) (interface{}, error) {
+	if i.depth >= maxCallDepth {
+		return nil, fmt.Errorf("maximum call depth exceeded")
+	}
+	i.depth++
+	defer func() {
+		i.depth--
+	}()
+	env := NewEnvironment(fn.Closure)
+	previous := i.env
+	i.env = env
+	defer func() {
+		i.env = previous
+	}()
+	for idx, param := range fn.Declaration.Params {
+		var value interface{}
+		switch {
+		case fn.Declaration.Rest && idx == len(fn.Declaration.Params)-1:
+			rest := make([]interface{}, 0)
+			if idx < len(args) {
+				rest = append(rest, args[idx:]...)
+			}
+			value = &ListValue{
+				Elements: rest,
+			}
+		case idx < len(args):
+			value = args[idx]
+		default:
+			value = i.evaluate(fn.Declaration.Defaults[idx])
+			if _, ok := value.(error); ok {
+				return value, nil
+			}
+		}
+		if fn.Declaration.Patterns[idx] == nil {
+			env.Define(param.Lexeme, value)
+		} else if err := i.destructure(fn.Declaration.Patterns[idx], value); err != nil {
+			return err, nil
+		}
+	}
+	return i.evaluate(fn.Declaration.Body), nil
+}
+
+// callableName names a callable in error messages
+func callableName(c Callable) string {
+	if nf, ok := c.(*NativeFunction); ok {
+		return nf.Name + "()"
+	}
+	return "lambda"
+}
+
+// arityText describes how many arguments a callable accepts
+func arityText(min, max int) string {
+	plural := func(n int) string {
+		if n == 1 {
+			return "1 argument"
+		}
+		return fmt.Sprintf("%d arguments", n)
+	}
+	switch {
+	case max < 0:
+		return "at least " + plural(min)
+	case min == max:
+		return plural(min)
+	}
+	return fmt.Sprintf("%d to %d arguments", min, max)
+}
+
+// listMethod returns the native method called name bound to list
+func listMethod(list *ListValue, name string) (*NativeFunction, bool) {
+	method := &NativeFunction{
+		Name: name,
+	}
+	switch name {
+	case "push":
+		method.ArgCount = 1
+		method.Fn = func(args []interface{}) (interface{}, error) {
+			list.Elements = append(list.Elements, args[0])
+			return nil, nil
+		}
+	case "pop":
+		method.Fn = func(args []interface{}) (interface{}, error) {
+			if len(list.Elements) == 0 {
+				return nil, fmt.Errorf("pop from empty list")
+			}
+			last := list.Elements[len(list.Elements)-1]
+			list.Elements = list.Elements[:len(list.Elements)-1]
+			return last, nil
+		}
+	case "len":
+		method.Fn = func(args []interface{}) (interface{}, error) {
+			return int64(len(list.Elements)), nil
+		}
+	case "insert":
+		method.ArgCount = 2
+		method.Fn = func(args []interface{}) (interface{}, error) {
+			// inserting right after the last element is allowed
+			position, err := listPosition(args[0], len(list.Elements)+1)
+			if err != nil {
+				return nil, err
+			}
+			list.Elements = append(list.Elements, nil)
+			copy(list.Elements[position+1:], list.Elements[position:])
+			list.Elements[position] = args[1]
+			return nil, nil
+		}
+	case "remove":
+		method.ArgCount = 1
+		method.Fn = func(args []interface{}) (interface{}, error) {
+			position, err := listPosition(args[0], len(list.Elements))
+			if err != nil {
+				return nil, err
+			}
+			removed := list.Elements[position]
+			list.Elements = append(list.Elements[:position], list.Elements[position+1:]...)
+			return removed, nil
+		}
+	case "contains":
+		method.ArgCount = 1
+		method.Fn = func(args []interface{}) (interface{}, error) {
+			for _, element := range list.Elements {
+				if ok, err := isEqual(element, args[0]); err == nil && ok {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	default:
+		return nil, false
+	}
+	return method, true
+}
+
+// mapMethod returns the native method called name bound to m
+func mapMethod(m *MapValue, name string) (*NativeFunction, bool) {
+	method := &NativeFunction{
+		Name: name,
+	}
+	switch name {
+	case "keys", "values":
+		method.Fn = func(args []interface{}) (interface{}, error) {
+			elements := make([]interface{}, 0, m.Len())
+			for _, entry := range m.Entries() {
+				if name == "keys" {
+					elements = append(elements, entry.Key)
+				} else {
+					elements = append(elements, entry.Value)
+				}
+			}
+			return &ListValue{
+				Elements: elements,
+			}, nil
+		}
+	case "has":
+		method.ArgCount = 1
+		method.Fn = func(args []interface{}) (interface{}, error) {
+			_, ok, err := m.Get(args[0])
+			return ok, err
+		}
+	case "delete":
+		method.ArgCount = 1
+		method.Fn = func(args []interface{}) (interface{}, error) {
+			return m.Delete(args[0])
+		}
+	case "len":
+		method.Fn = func(args []interface{}) (interface{}, error) {
+			return int64(m.Len()), nil
+		}
+	default:
+		return nil, false
+	}
+	return method, true
+}
+
+// listPosition resolves a possibly negative index into a position in [0, length)
+func listPosition(index interface{}, length int) (int, error) {
+	position, ok := index.(int64)
+	if !ok {
+		if isInteger(index) {
+			return 0, fmt.Errorf("list index %s out of range", stringify(index))
+		}
+		return 0, fmt.Errorf("list indices must be integers, not %s", nonInteger(index))
+	}
+	if position < 0 {
+		position += int64(length)
+	}
+	if position < 0 || position >= int64(length) {
+		return 0, fmt.Errorf("list index %s out of range for length %d", stringify(index), length)
+	}
+	return int(position), nil
+}
+
+// nonInteger names a value used where an integer is needed: a float by its
+// value, since its type alone would read as a number, and anything else by
+// its type
+func nonInteger(val interface{}) string {
+	if _, ok := val.(float64); ok {
+		return formatNumber(val)
+	}
+	return typeName(val)
+}
+
+// sliceBounds resolves the possibly negative or missing bounds of a slice
+func sliceBounds(start, end interface{}, length int) (int, int, error) {
+	bounds := [2]int{0, length}
+	for idx, bound := range []interface{}{start, end} {
+		if bound == nil {
+			continue
+		}
+		value, ok := bound.(int64)
+		if !ok {
+			if isInteger(bound) {
+				return 0, 0, fmt.Errorf("slice bound %s out of range", stringify(bound))
+			}
+			return 0, 0, fmt.Errorf("slice bounds must be integers, not %s", nonInteger(bound))
+		}
+		if value < 0 {
+			value += int64(length)
+		}
+		if value < 0 || value > int64(length) {
+			return 0, 0, fmt.Errorf("slice bound %s out of range for length %d", stringify(bound), length)
+		}
+		bounds[idx] = int(value)
+	}
+	if bounds[0] > bounds[1] {
+		return 0, 0, fmt.Errorf("slice bounds out of range [%d:%d]", bounds[0], bounds[1])
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// repr is the representation of a value inside a collection, where strings are quoted
+func repr(val interface{}, seen map[interface{}]bool) string {
+	switch v := val.(type) {
+	case string:
+		return strconv.Quote(v)
+	case *ListValue:
+		if seen[v] {
+			return "[...]"
+		}
+		seen[v] = true
+		defer delete(seen, v)
+		elements := make([]string, 0, len(v.Elements))
+		for _, element := range v.Elements {
+			elements = append(elements, repr(element, seen))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *MapValue:
+		if seen[v] {
+			return "{...}"
+		}
+		seen[v] = true
+		defer delete(seen, v)
+		entries := make([]string, 0, v.Len())
+		for _, entry := range v.Entries() {
+			entries = append(entries, repr(entry.Key, seen)+": "+repr(entry.Value, seen))
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+	return stringify(val)
+}
+
+func typeName(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64, int64, *big.Int:
+		return "number"
+	case *ListValue:
+		return "list"
+	case *MapValue:
+		return "map"
+	case Callable:
+		return "function"
+	}
+	return reflect.TypeOf(val).String()
+}
@@ -13,11 +13,29 @@ type Parser struct {
 	Tokens   []lexer.Token
 	Position int
 	HadError bool
+	// guard is set while parsing a match guard outside of any brackets, where
+	// "(x) =>" is the guard (x) followed by the arm's arrow, not a lambda
+	guard bool
+}
+
+// enclosed clears guard for an expression inside brackets, where the arm's
+// arrow cannot appear, and returns a function that restores it
+func (p *Parser) enclosed() func() {
+	guard := p.guard
+	p.guard = false
+	return func() {
+		p.guard = guard
+	}
 }
 
 func (p *Parser) Parse() exprVisitors.Expr[any, interface{}] {
 	expr, err := p.Expression()
+	if err == nil && !p.IsAtEnd() {
+		// the source is a single expression so anything after it is an error
+		err = p.ErrorAt(p.Peek(), "Expect end of expression.")
+	}
 	if err != nil {
+		log.Print(err.Error())
 		p.HadError = true
 	}
 	return expr
@@ -32,17 +50,17 @@ func (p *Parser) Expression() (exprVisitors.Expr[any, interface{}], error) {
 }
 func (p *Parser) Comma() (exprVisitors.Expr[any, interface{}], error) {
 	if p.MissingLeftOperand([]lexer.TokenType{lexer.TokenComma}) {
-		right, _ := p.Ternary()
+		right, _ := p.Assignment()
 		return right, nil
 	}
 
-	newExpr, err := p.Ternary()
+	newExpr, err := p.Assignment()
 	if err != nil {
 		return nil, err
 	}
 	for {
 		if p.Match([]lexer.TokenType{lexer.TokenComma}) {
-			rightExpr, err := p.Ternary()
+			rightExpr, err := p.Assignment()
 			if err != nil {
 				return nil, err
 			}
@@ -57,6 +75,25 @@ func (p *Parser) Comma() (exprVisitors.Expr[any, interface{}], error) {
 	return newExpr, nil
 }
 
+func (p *Parser) Assignment() (exprVisitors.Expr[any, interface{}], error) {
+	assignmentOperators := []lexer.TokenType{lexer.TokenEqual, lexer.TokenPlusEqual, lexer.TokenMinusEqual, lexer.TokenStarEqual, lexer.TokenSlashEqual, lexer.TokenStarStarEqual}
+
+	target, err := p.Ternary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.Match(assignmentOperators) {
+		return target, nil
+	}
+	// assignment is right-associative
+	operator := p.Previous()
+	value, err := p.Assignment()
+	if err != nil {
+		return nil, err
+	}
+	return p.AssignTo(target, operator, value, false), nil
+}
+
 func (p *Parser) Ternary() (exprVisitors.Expr[any, interface{}], error) {
 	if p.MissingLeftOperand([]lexer.TokenType{lexer.TokenQuestionMark}) {
 		expr, _ := p.Expression()
@@ -67,7 +104,7 @@ func (p *Parser) Ternary() (exprVisitors.Expr[any, interface{}], error) {
 		return expr, nil
 	}
 
-	left, err := p.Equality()
+	left, err := p.Coalesce()
 	if err != nil {
 		return nil, err
 	}
@@ -117,6 +154,32 @@ func (p *Parser) Ternary() (exprVisitors.Expr[any, interface{}], error) {
 		Right:  right,
 	}, nil
 }
+func (p *Parser) Coalesce() (exprVisitors.Expr[any, interface{}], error) {
+	coalesceOperators := []lexer.TokenType{lexer.TokenQuestionQuestion}
+
+	if p.MissingLeftOperand(coalesceOperators) {
+		right, _ := p.Equality()
+		return right, nil
+	}
+
+	newExpr, err := p.Equality()
+	if err != nil {
+		return nil, err
+	}
+	for p.Match(coalesceOperators) {
+		operator := p.Previous()
+		rightExpr, err := p.Equality()
+		if err != nil {
+			return nil, err
+		}
+		newExpr = &exprVisitors.Binary[any, interface{}]{
+			Left:     newExpr,
+			Operator: operator,
+			Right:    rightExpr,
+		}
+	}
+	return newExpr, nil
+}
 func (p *Parser) Equality() (exprVisitors.Expr[any, interface{}], error) {
 	equalityOperators := []lexer.TokenType{lexer.TokenBangEqual, lexer.TokenEqualEqual}
 
@@ -153,6 +216,126 @@ func (p *Parser) Comparison() (exprVisitors.Expr[any, interface{}], error) {
 	compareOperators := []lexer.TokenType{lexer.TokenGreater, lexer.TokenGreaterEqual, lexer.TokenLess, lexer.TokenLessEqual}
 
 	if p.MissingLeftOperand(compareOperators) {
+		right, _ := p.BitOr()
+		return right, nil
+	}
+
+	newExpr, err := p.BitOr()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.Match(compareOperators) {
+			operator := p.Previous()
+			rightExpr, err := p.BitOr()
+			if err != nil {
+				return nil, err
+			}
+			newExpr = &exprVisitors.Binary[any, interface{}]{
+				Left:     newExpr,
+				Operator: operator,
+				Right:    rightExpr,
+			}
+		} else {
+			break
+		}
+	}
+	return newExpr, nil
+}
+func (p *Parser) BitOr() (exprVisitors.Expr[any, interface{}], error) {
+	bitOrOperators := []lexer.TokenType{lexer.TokenPipe}
+
+	if p.MissingLeftOperand(bitOrOperators) {
+		right, _ := p.BitXor()
+		return right, nil
+	}
+
+	newExpr, err := p.BitXor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.Match(bitOrOperators) {
+			operator := p.Previous()
+			rightExpr, err := p.BitXor()
+			if err != nil {
+				return nil, err
+			}
+			newExpr = &exprVisitors.Binary[any, interface{}]{
+				Left:     newExpr,
+				Operator: operator,
+				Right:    rightExpr,
+			}
+		} else {
+			break
+		}
+	}
+	return newExpr, nil
+}
+func (p *Parser) BitXor() (exprVisitors.Expr[any, interface{}], error) {
+	bitXorOperators := []lexer.TokenType{lexer.TokenCaret}
+
+	if p.MissingLeftOperand(bitXorOperators) {
+		right, _ := p.BitAnd()
+		return right, nil
+	}
+
+	newExpr, err := p.BitAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.Match(bitXorOperators) {
+			operator := p.Previous()
+			rightExpr, err := p.BitAnd()
+			if err != nil {
+				return nil, err
+			}
+			newExpr = &exprVisitors.Binary[any, interface{}]{
+				Left:     newExpr,
+				Operator: operator,
+				Right:    rightExpr,
+			}
+		} else {
+			break
+		}
+	}
+	return newExpr, nil
+}
+func (p *Parser) BitAnd() (exprVisitors.Expr[any, interface{}], error) {
+	bitAndOperators := []lexer.TokenType{lexer.TokenAmpersand}
+
+	if p.MissingLeftOperand(bitAndOperators) {
+		right, _ := p.Shift()
+		return right, nil
+	}
+
+	newExpr, err := p.Shift()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.Match(bitAndOperators) {
+			operator := p.Previous()
+			rightExpr, err := p.Shift()
+			if err != nil {
+				return nil, err
+			}
+			newExpr = &exprVisitors.Binary[any, interface{}]{
+				Left:     newExpr,
+				Operator: operator,
+				Right:    rightExpr,
+			}
+		} else {
+			break
+		}
+	}
+	return newExpr, nil
+}
+func (p *Parser) Shift() (exprVisitors.Expr[any, interface{}], error) {
+	shiftOperators := []lexer.TokenType{lexer.TokenLessLess, lexer.TokenGreaterGreater}
+
+	if p.MissingLeftOperand(shiftOperators) {
 		right, _ := p.Term()
 		return right, nil
 	}
@@ -162,7 +345,7 @@ func (p *Parser) Comparison() (exprVisitors.Expr[any, interface{}], error) {
 		return nil, err
 	}
 	for {
-		if p.Match(compareOperators) {
+		if p.Match(shiftOperators) {
 			operator := p.Previous()
 			rightExpr, err := p.Term()
 			if err != nil {
@@ -210,7 +393,7 @@ func (p *Parser) Term() (exprVisitors.Expr[any, interface{}], error) {
 	return newExpr, nil
 }
 func (p *Parser) Factor() (exprVisitors.Expr[any, interface{}], error) {
-	factorOperators := []lexer.TokenType{lexer.TokenSlash, lexer.TokenStar}
+	factorOperators := []lexer.TokenType{lexer.TokenSlash, lexer.TokenStar, lexer.TokenPercent, lexer.TokenTildeSlash}
 
 	if p.MissingLeftOperand(factorOperators) {
 		right, _ := p.Expo()
@@ -253,6 +436,7 @@ func (p *Parser) Expo() (exprVisitors.Expr[any, interface{}], error) {
 	if !p.Match([]lexer.TokenType{lexer.TokenStarStar}) {
 		return left, nil
 	}
+	operator := p.Previous()
 	if p.Peek().Type == lexer.TokenEOF {
 		parseError := ParserError{
 			Line:    p.Peek().Line,
@@ -263,7 +447,7 @@ func (p *Parser) Expo() (exprVisitors.Expr[any, interface{}], error) {
 		p.HadError = true
 		return &exprVisitors.Binary[any, interface{}]{
 			Left:     left,
-			Operator: p.Previous(),
+			Operator: operator,
 			Right:    nil,
 		}, nil
 	}
@@ -273,13 +457,22 @@ func (p *Parser) Expo() (exprVisitors.Expr[any, interface{}], error) {
 	}
 	return &exprVisitors.Binary[any, interface{}]{
 		Left:     left,
-		Operator: p.Tokens[p.Position-2],
+		Operator: operator,
 		Right:    right,
 	}, nil
 }
 func (p *Parser) Unary() (exprVisitors.Expr[any, interface{}], error) {
-	unaryOperators := []lexer.TokenType{lexer.TokenBang, lexer.TokenMinus}
+	unaryOperators := []lexer.TokenType{lexer.TokenBang, lexer.TokenMinus, lexer.TokenTilde}
+	incrementOperators := []lexer.TokenType{lexer.TokenPlusPlus, lexer.TokenMinusMinus}
 
+	if p.Match(incrementOperators) {
+		operator := p.Previous()
+		target, err := p.Unary()
+		if err != nil {
+			return nil, err
+		}
+		return p.AssignTo(target, operator, nil, true), nil
+	}
 	if p.Match(unaryOperators) {
 		operator := p.Previous()
 		expr, err := p.Unary()
@@ -291,13 +484,158 @@ func (p *Parser) Unary() (exprVisitors.Expr[any, interface{}], error) {
 			Right:    expr,
 		}, nil
 	}
-	return p.Primary()
+	return p.Postfix()
+}
+func (p *Parser) Postfix() (exprVisitors.Expr[any, interface{}], error) {
+	incrementOperators := []lexer.TokenType{lexer.TokenPlusPlus, lexer.TokenMinusMinus}
+
+	expr, err := p.Call()
+	if err != nil {
+		return nil, err
+	}
+	for p.Match(incrementOperators) {
+		expr = p.AssignTo(expr, p.Previous(), nil, false)
+	}
+	return expr, nil
+}
+func (p *Parser) Call() (exprVisitors.Expr[any, interface{}], error) {
+	expr, err := p.Primary()
+	if err != nil {
+		return nil, err
+	}
+	// a chain containing "?." evaluates to nil as soon as one of them finds nil
+	optional := false
+	for {
+		switch {
+		case p.Match([]lexer.TokenType{lexer.TokenLeftParen}):
+			expr, err = p.FinishCall(expr)
+		case p.Match([]lexer.TokenType{lexer.TokenLeftBracket}):
+			expr, err = p.FinishIndex(expr)
+		case p.Match([]lexer.TokenType{lexer.TokenDot}):
+			var name lexer.Token
+			name, err = p.Consume(lexer.TokenIdentifier, "Expect property name after '.'.")
+			expr = &exprVisitors.Get[any, interface{}]{
+				Object: expr,
+				Name:   name,
+			}
+		case p.Match([]lexer.TokenType{lexer.TokenQuestionDot}):
+			var name lexer.Token
+			name, err = p.Consume(lexer.TokenIdentifier, "Expect property name after '?.'.")
+			expr = &exprVisitors.Get[any, interface{}]{
+				Object:   expr,
+				Name:     name,
+				Optional: true,
+			}
+			optional = true
+		default:
+			if optional {
+				expr = &exprVisitors.OptionalChain[any, interface{}]{
+					Expression: expr,
+				}
+			}
+			return expr, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+func (p *Parser) FinishCall(callee exprVisitors.Expr[any, interface{}]) (exprVisitors.Expr[any, interface{}], error) {
+	paren := p.Previous()
+	arguments, err := p.Elements(lexer.TokenRightParen, "Expect ')' after arguments.")
+	if err != nil {
+		return nil, err
+	}
+	return &exprVisitors.Call[any, interface{}]{
+		Callee:    callee,
+		Paren:     paren,
+		Arguments: arguments,
+	}, nil
+}
+
+// FinishIndex parses either an index "[i]" or a slice "[start:end]" whose
+// bounds are both optional
+func (p *Parser) FinishIndex(object exprVisitors.Expr[any, interface{}]) (exprVisitors.Expr[any, interface{}], error) {
+	defer p.enclosed()()
+	bracket := p.Previous()
+	var start exprVisitors.Expr[any, interface{}]
+	var err error
+	if !p.Check(lexer.TokenColon) {
+		// the bound is a ternary so that its colon is not taken for the slice's
+		start, err = p.Ternary()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !p.Match([]lexer.TokenType{lexer.TokenColon}) {
+		_, err = p.Consume(lexer.TokenRightBracket, "Expect ']' after index.")
+		if err != nil {
+			return nil, err
+		}
+		return &exprVisitors.Index[any, interface{}]{
+			Object:  object,
+			Bracket: bracket,
+			Index:   start,
+		}, nil
+	}
+	var end exprVisitors.Expr[any, interface{}]
+	if !p.Check(lexer.TokenRightBracket) {
+		end, err = p.Ternary()
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = p.Consume(lexer.TokenRightBracket, "Expect ']' after slice.")
+	if err != nil {
+		return nil, err
+	}
+	return &exprVisitors.Slice[any, interface{}]{
+		Object:  object,
+		Bracket: bracket,
+		Start:   start,
+		End:     end,
+	}, nil
+}
+
+// Elements parses a comma separated list of expressions, any of which may be
+// spread, up to the closing token, allowing a trailing comma
+func (p *Parser) Elements(closing lexer.TokenType, message string) ([]exprVisitors.Expr[any, interface{}], error) {
+	defer p.enclosed()()
+	elements := make([]exprVisitors.Expr[any, interface{}], 0)
+	for !p.Check(closing) {
+		spread := p.Match([]lexer.TokenType{lexer.TokenEllipsis})
+		ellipsis := p.Previous()
+		// elements are parsed above the comma operator so that commas separate them
+		element, err := p.Assignment()
+		if err != nil {
+			return nil, err
+		}
+		if spread {
+			element = &exprVisitors.Spread[any, interface{}]{
+				Ellipsis:   ellipsis,
+				Expression: element,
+			}
+		}
+		elements = append(elements, element)
+		if !p.Match([]lexer.TokenType{lexer.TokenComma}) {
+			break
+		}
+	}
+	_, err := p.Consume(closing, message)
+	if err != nil {
+		return nil, err
+	}
+	return elements, nil
 }
 func (p *Parser) Primary() (exprVisitors.Expr[any, interface{}], error) {
+	if !p.guard && p.Check(lexer.TokenLeftParen) && p.LambdaAhead() {
+		return p.Lambda()
+	}
 
 	if p.Match([]lexer.TokenType{lexer.TokenLeftParen}) {
 		// after matching an open parentheses we parse the expression inside of it
 		// and log an error if the expression is not followed by a closing parentheses
+		defer p.enclosed()()
 		expr, err := p.Expression()
 		if err != nil {
 			return nil, err
@@ -310,33 +648,77 @@ func (p *Parser) Primary() (exprVisitors.Expr[any, interface{}], error) {
 			Expression: expr,
 		}, nil
 	}
+	if p.Match([]lexer.TokenType{lexer.TokenLeftBracket}) {
+		bracket := p.Previous()
+		elements, err := p.Elements(lexer.TokenRightBracket, "Expect ']' after list elements.")
+		if err != nil {
+			return nil, err
+		}
+		return &exprVisitors.List[any, interface{}]{
+			Bracket:  bracket,
+			Elements: elements,
+		}, nil
+	}
+	if p.Match([]lexer.TokenType{lexer.TokenLeftBrace}) {
+		return p.Map(p.Previous())
+	}
+	if p.Match([]lexer.TokenType{lexer.TokenMatch}) {
+		return p.MatchExpression(p.Previous())
+	}
+	if p.Match([]lexer.TokenType{lexer.TokenIdentifier}) {
+		return &exprVisitors.Variable[any, interface{}]{
+			Name: p.Previous(),
+		}, nil
+	}
 	if p.Match([]lexer.TokenType{lexer.TokenTrue}) {
 		return &exprVisitors.Literal[any, interface{}]{
 			Value: true,
+			Token: p.Previous(),
 		}, nil
 	}
 	if p.Match([]lexer.TokenType{lexer.TokenFalse}) {
 		return &exprVisitors.Literal[any, interface{}]{
 			Value: false,
+			Token: p.Previous(),
 		}, nil
 	}
 	if p.Match([]lexer.TokenType{lexer.TokenNil}) {
 		return &exprVisitors.Literal[any, interface{}]{
 			Value: "nil",
+			Token: p.Previous(),
 		}, nil
 	}
 	if p.Match([]lexer.TokenType{lexer.TokenStringLiteral}) {
 		return &exprVisitors.Literal[any, interface{}]{
 			Value: p.Previous().Literal,
 			Type:  "string",
+			Token: p.Previous(),
 		}, nil
 	}
+	if p.Match([]lexer.TokenType{lexer.TokenInterpolation}) {
+		return p.Interpolation(p.Previous())
+	}
 	if p.Match([]lexer.TokenType{lexer.TokenNumberLiteral}) {
+		// literals without a fraction or exponent evaluate to integers
+		litType := "number"
+		if _, ok := lexer.ParseInteger(p.Previous().Lexeme); ok {
+			litType = "integer"
+		}
 		return &exprVisitors.Literal[any, interface{}]{
 			Value: p.Previous().Literal,
-			Type:  "number",
+			Type:  litType,
+			Token: p.Previous(),
 		}, nil
 	}
+	if p.Match([]lexer.TokenType{lexer.TokenBreak, lexer.TokenContinue}) {
+		// the grammar has no loops yet so there is never an enclosing one
+		parseError := ParserError{
+			Line:    p.Previous().Line,
+			Message: "Can't use '" + p.Previous().Lexeme + "' outside of a loop.",
+		}
+		msg := parseError.Report(p.Previous())
+		return nil, errors.New(msg)
+	}
 	parseError := ParserError{
 		Line:    p.Peek().Line,
 		Message: "Expecting expression",
@@ -345,6 +727,481 @@ func (p *Parser) Primary() (exprVisitors.Expr[any, interface{}], error) {
 	return nil, errors.New(msg)
 }
 
+func (p *Parser) Interpolation(token lexer.Token) (exprVisitors.Expr[any, interface{}], error) {
+	parts := make([]exprVisitors.Expr[any, interface{}], 0)
+	for _, part := range token.Literal.([]lexer.InterpolationPart) {
+		if part.Tokens == nil {
+			parts = append(parts, &exprVisitors.Literal[any, interface{}]{
+				Value: part.Text,
+				Type:  "string",
+				Token: token,
+			})
+			continue
+		}
+		// the embedded expression is parsed on its own
+		embedded := Parser{
+			Tokens: part.Tokens,
+		}
+		expr, err := embedded.Expression()
+		if err == nil && !embedded.IsAtEnd() {
+			parseError := ParserError{
+				Line:    embedded.Peek().Line,
+				Message: "Expect '}' after interpolated expression.",
+			}
+			err = errors.New(parseError.Report(embedded.Peek()))
+		}
+		if err != nil {
+			p.HadError = true
+			return nil, err
+		}
+		if embedded.HadError {
+			p.HadError = true
+		}
+		parts = append(parts, expr)
+	}
+	return &exprVisitors.Interpolation[any, interface{}]{
+		Token: token,
+		Parts: parts,
+	}, nil
+}
+
+// LambdaAhead looks past the '(' at the current position for a parameter
+// list followed by "=>". Only then is the parenthesis the start of a lambda
+// rather than of a grouping, so that "(x > y) =>" in a match guard is not
+// taken for a lambda.
+func (p *Parser) LambdaAhead() bool {
+	position := p.Position + 1
+	typeAt := func(offset int) lexer.TokenType {
+		if offset >= len(p.Tokens) {
+			return lexer.TokenEOF
+		}
+		return p.Tokens[offset].Type
+	}
+	// skip advances position past a bracketed group, or up to the next
+	// parameter when stop is set
+	skip := func(stop bool) bool {
+		depth := 0
+		for ; depth > 0 || !stop || (typeAt(position) != lexer.TokenComma && typeAt(position) != lexer.TokenRightParen); position++ {
+			switch typeAt(position) {
+			case lexer.TokenLeftParen, lexer.TokenLeftBracket, lexer.TokenLeftBrace:
+				depth++
+			case lexer.TokenRightParen, lexer.TokenRightBracket, lexer.TokenRightBrace:
+				depth--
+				if depth == 0 && !stop {
+					position++
+					return true
+				}
+			case lexer.TokenEOF:
+				return false
+			}
+		}
+		return true
+	}
+	for typeAt(position) != lexer.TokenRightParen {
+		switch typeAt(position) {
+		case lexer.TokenLeftBracket, lexer.TokenLeftBrace:
+			// a destructured parameter
+			if !skip(false) {
+				return false
+			}
+		case lexer.TokenEllipsis:
+			position++
+			fallthrough
+		default:
+			if typeAt(position) != lexer.TokenIdentifier {
+				return false
+			}
+			position++
+		}
+		if typeAt(position) == lexer.TokenEqual {
+			// skip the default value up to the next parameter
+			position++
+			if !skip(true) {
+				return false
+			}
+		}
+		if typeAt(position) != lexer.TokenComma {
+			break
+		}
+		position++
+	}
+	return typeAt(position) == lexer.TokenRightParen && typeAt(position+1) == lexer.TokenArrow
+}
+
+// Lambda parses "(params) => body". Parameters with a default value may only
+// be followed by other such parameters and a rest parameter must come last.
+func (p *Parser) Lambda() (exprVisitors.Expr[any, interface{}], error) {
+	paren := p.Advance()
+	lambda := &exprVisitors.Lambda[any, interface{}]{
+		Paren:    paren,
+		Params:   make([]lexer.Token, 0),
+		Defaults: make([]exprVisitors.Expr[any, interface{}], 0),
+		Patterns: make([]exprVisitors.Pattern[any, interface{}], 0),
+	}
+	// the names bound by all parameters, destructured or not
+	bound := make(map[string]bool)
+	for !p.Check(lexer.TokenRightParen) {
+		if lambda.Rest {
+			return nil, p.ErrorAt(p.Peek(), "Rest parameter must be last.")
+		}
+		lambda.Rest = p.Match([]lexer.TokenType{lexer.TokenEllipsis})
+		var pattern exprVisitors.Pattern[any, interface{}]
+		var param lexer.Token
+		var err error
+		if !lambda.Rest && (p.Check(lexer.TokenLeftBracket) || p.Check(lexer.TokenLeftBrace)) {
+			// a destructured parameter is known by its opening bracket
+			param = p.Peek()
+			pattern, err = p.Pattern(bound, true)
+		} else {
+			param, err = p.Consume(lexer.TokenIdentifier, "Expect parameter name.")
+			if err == nil && bound[param.Lexeme] {
+				err = p.ErrorAt(param, "Duplicate parameter name.")
+			}
+			bound[param.Lexeme] = true
+		}
+		if err != nil {
+			return nil, err
+		}
+		var def exprVisitors.Expr[any, interface{}]
+		if p.Match([]lexer.TokenType{lexer.TokenEqual}) {
+			if lambda.Rest {
+				return nil, p.ErrorAt(p.Previous(), "Rest parameter can't have a default value.")
+			}
+			def, err = p.Assignment()
+			if err != nil {
+				return nil, err
+			}
+		} else if !lambda.Rest && len(lambda.Defaults) > 0 && lambda.Defaults[len(lambda.Defaults)-1] != nil {
+			return nil, p.ErrorAt(param, "Parameter without a default value follows one with a default value.")
+		}
+		lambda.Params = append(lambda.Params, param)
+		lambda.Defaults = append(lambda.Defaults, def)
+		lambda.Patterns = append(lambda.Patterns, pattern)
+		if !p.Match([]lexer.TokenType{lexer.TokenComma}) {
+			break
+		}
+	}
+	_, err := p.Consume(lexer.TokenRightParen, "Expect ')' after parameters.")
+	if err != nil {
+		return nil, err
+	}
+	lambda.Arrow, err = p.Consume(lexer.TokenArrow, "Expect '=>' after parameters.")
+	if err != nil {
+		return nil, err
+	}
+	lambda.Body, err = p.Assignment()
+	if err != nil {
+		return nil, err
+	}
+	return lambda, nil
+}
+
+// ErrorAt builds the error for a problem found at token
+func (p *Parser) ErrorAt(token lexer.Token, message string) error {
+	parseError := ParserError{
+		Line:    token.Line,
+		Message: message,
+	}
+	return errors.New(parseError.Report(token))
+}
+
+// MatchExpression parses "match subject { case pattern if guard => body ... }".
+// Arms after one that matches everything can never run and are warned about.
+func (p *Parser) MatchExpression(keyword lexer.Token) (exprVisitors.Expr[any, interface{}], error) {
+	subject, err := p.Ternary()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.Consume(lexer.TokenLeftBrace, "Expect '{' after match subject.")
+	if err != nil {
+		return nil, err
+	}
+	defer p.enclosed()()
+	match := &exprVisitors.Match[any, interface{}]{
+		Keyword: keyword,
+		Subject: subject,
+	}
+	catchAll := false
+	for !p.Check(lexer.TokenRightBrace) {
+		arm := &exprVisitors.MatchArm[any, interface{}]{}
+		arm.Case, err = p.Consume(lexer.TokenCase, "Expect 'case' before match arm.")
+		if err != nil {
+			return nil, err
+		}
+		if catchAll {
+			parseWarning := ParserError{
+				Line:    arm.Case.Line,
+				Message: "Unreachable match arm after a pattern that matches everything.",
+			}
+			log.Print(parseWarning.Warn(arm.Case))
+		}
+		arm.Pattern, err = p.Pattern(make(map[string]bool), false)
+		if err != nil {
+			return nil, err
+		}
+		if p.Match([]lexer.TokenType{lexer.TokenIf}) {
+			p.guard = true
+			arm.Guard, err = p.Assignment()
+			p.guard = false
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err = p.Consume(lexer.TokenArrow, "Expect '=>' after match pattern.")
+		if err != nil {
+			return nil, err
+		}
+		arm.Body, err = p.Assignment()
+		if err != nil {
+			return nil, err
+		}
+		catchAll = catchAll || (arm.Guard == nil && exprVisitors.CatchAll[any, interface{}](arm.Pattern))
+		match.Arms = append(match.Arms, arm)
+	}
+	_, err = p.Consume(lexer.TokenRightBrace, "Expect '}' after match arms.")
+	if err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// Pattern parses the pattern of a match arm or, when destructuring, of a
+// parameter. Destructuring patterns bind names but cannot contain literals,
+// and their elements and entries may have default values. bound holds the
+// names bound so far, none of which may be bound twice.
+func (p *Parser) Pattern(bound map[string]bool, destructuring bool) (exprVisitors.Pattern[any, interface{}], error) {
+	switch {
+	case p.Match([]lexer.TokenType{lexer.TokenIdentifier}):
+		name := p.Previous()
+		if name.Lexeme == "_" {
+			return &exprVisitors.WildcardPattern[any, interface{}]{
+				Token: name,
+			}, nil
+		}
+		if err := p.Bind(bound, name); err != nil {
+			return nil, err
+		}
+		return &exprVisitors.BindingPattern[any, interface{}]{
+			Name: name,
+		}, nil
+	case p.Match([]lexer.TokenType{lexer.TokenLeftBracket}):
+		list := &exprVisitors.ListPattern[any, interface{}]{
+			Bracket: p.Previous(),
+		}
+		for !p.Check(lexer.TokenRightBracket) {
+			if p.Match([]lexer.TokenType{lexer.TokenEllipsis}) {
+				rest, err := p.RestPattern(bound)
+				if err != nil {
+					return nil, err
+				}
+				list.Rest = rest
+				break
+			}
+			element, err := p.Pattern(bound, destructuring)
+			if err != nil {
+				return nil, err
+			}
+			element, err = p.DefaultPattern(element, destructuring)
+			if err != nil {
+				return nil, err
+			}
+			list.Elements = append(list.Elements, element)
+			if !p.Match([]lexer.TokenType{lexer.TokenComma}) {
+				break
+			}
+		}
+		_, err := p.Consume(lexer.TokenRightBracket, "Expect ']' after list pattern.")
+		if err != nil {
+			return nil, err
+		}
+		return list, nil
+	case p.Match([]lexer.TokenType{lexer.TokenLeftBrace}):
+		m := &exprVisitors.MapPattern[any, interface{}]{
+			Brace: p.Previous(),
+		}
+		for !p.Check(lexer.TokenRightBrace) {
+			if p.Match([]lexer.TokenType{lexer.TokenEllipsis}) {
+				rest, err := p.RestPattern(bound)
+				if err != nil {
+					return nil, err
+				}
+				m.Rest = rest
+				break
+			}
+			var key exprVisitors.Expr[any, interface{}]
+			var value exprVisitors.Pattern[any, interface{}]
+			var err error
+			if p.Check(lexer.TokenIdentifier) {
+				// the shorthand {name} binds the entry with the key "name"
+				value, err = p.Pattern(bound, destructuring)
+				if _, ok := value.(*exprVisitors.WildcardPattern[any, interface{}]); ok {
+					return nil, p.ErrorAt(p.Previous(), "Expect name or key in map pattern.")
+				}
+			} else {
+				var literal *exprVisitors.LiteralPattern[any, interface{}]
+				literal, err = p.LiteralPattern()
+				if err != nil {
+					return nil, err
+				}
+				key = literal.Value
+				_, err = p.Consume(lexer.TokenColon, "Expect ':' after map pattern key.")
+				if err != nil {
+					return nil, err
+				}
+				value, err = p.Pattern(bound, destructuring)
+			}
+			if err != nil {
+				return nil, err
+			}
+			value, err = p.DefaultPattern(value, destructuring)
+			if err != nil {
+				return nil, err
+			}
+			m.Keys = append(m.Keys, key)
+			m.Values = append(m.Values, value)
+			if !p.Match([]lexer.TokenType{lexer.TokenComma}) {
+				break
+			}
+		}
+		_, err := p.Consume(lexer.TokenRightBrace, "Expect '}' after map pattern.")
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	if destructuring {
+		return nil, p.ErrorAt(p.Peek(), "Expect name, list pattern or map pattern.")
+	}
+	return p.LiteralPattern()
+}
+
+// DefaultPattern parses the "= value" that may follow a destructured element
+func (p *Parser) DefaultPattern(pattern exprVisitors.Pattern[any, interface{}], destructuring bool) (exprVisitors.Pattern[any, interface{}], error) {
+	if !destructuring || !p.Match([]lexer.TokenType{lexer.TokenEqual}) {
+		return pattern, nil
+	}
+	def, err := p.Assignment()
+	if err != nil {
+		return nil, err
+	}
+	return &exprVisitors.DefaultPattern[any, interface{}]{
+		Pattern: pattern,
+		Default: def,
+	}, nil
+}
+
+// RestPattern parses the name after "..." which must end the pattern
+func (p *Parser) RestPattern(bound map[string]bool) (*lexer.Token, error) {
+	rest, err := p.Consume(lexer.TokenIdentifier, "Expect name after '...'.")
+	if err != nil {
+		return nil, err
+	}
+	if rest.Lexeme != "_" {
+		if err := p.Bind(bound, rest); err != nil {
+			return nil, err
+		}
+	}
+	return &rest, nil
+}
+
+// Bind records a name bound by a pattern or a parameter list
+func (p *Parser) Bind(bound map[string]bool, name lexer.Token) error {
+	if bound[name.Lexeme] {
+		return p.ErrorAt(name, "Duplicate binding name.")
+	}
+	bound[name.Lexeme] = true
+	return nil
+}
+
+// LiteralPattern parses a literal, allowing a minus sign in front of numbers
+func (p *Parser) LiteralPattern() (*exprVisitors.LiteralPattern[any, interface{}], error) {
+	literals := []lexer.TokenType{lexer.TokenNumberLiteral, lexer.TokenStringLiteral, lexer.TokenTrue, lexer.TokenFalse, lexer.TokenNil}
+	if p.Match([]lexer.TokenType{lexer.TokenMinus}) {
+		operator := p.Previous()
+		if !p.Check(lexer.TokenNumberLiteral) {
+			return nil, p.ErrorAt(p.Peek(), "Expect number after '-' in pattern.")
+		}
+		number, err := p.Primary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprVisitors.LiteralPattern[any, interface{}]{
+			Value: &exprVisitors.Unary[any, interface{}]{
+				Operator: operator,
+				Right:    number,
+			},
+		}, nil
+	}
+	if !slices.Contains(literals, p.Peek().Type) {
+		return nil, p.ErrorAt(p.Peek(), "Expect pattern.")
+	}
+	value, err := p.Primary()
+	if err != nil {
+		return nil, err
+	}
+	return &exprVisitors.LiteralPattern[any, interface{}]{
+		Value: value,
+	}, nil
+}
+
+// Map parses the entries of a map literal. The grammar has no block statements
+// so a '{' that starts an expression always opens a map.
+func (p *Parser) Map(brace lexer.Token) (exprVisitors.Expr[any, interface{}], error) {
+	defer p.enclosed()()
+	keys := make([]exprVisitors.Expr[any, interface{}], 0)
+	values := make([]exprVisitors.Expr[any, interface{}], 0)
+	for !p.Check(lexer.TokenRightBrace) {
+		// keys are parsed as ternaries so that their colon is not taken for the entry's
+		key, err := p.Ternary()
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.Consume(lexer.TokenColon, "Expect ':' after map key.")
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.Assignment()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+		if !p.Match([]lexer.TokenType{lexer.TokenComma}) {
+			break
+		}
+	}
+	_, err := p.Consume(lexer.TokenRightBrace, "Expect '}' after map entries.")
+	if err != nil {
+		return nil, err
+	}
+	return &exprVisitors.Map[any, interface{}]{
+		Brace:  brace,
+		Keys:   keys,
+		Values: values,
+	}, nil
+}
+
+// AssignTo desugars an assignment (value set) or an increment or decrement
+// (value nil) of target so that the target is evaluated only once
+func (p *Parser) AssignTo(target exprVisitors.Expr[any, interface{}], operator lexer.Token, value exprVisitors.Expr[any, interface{}], prefix bool) exprVisitors.Expr[any, interface{}] {
+	if index, ok := target.(*exprVisitors.Index[any, interface{}]); ok {
+		return &exprVisitors.SetIndex[any, interface{}]{
+			Target:   index,
+			Operator: operator,
+			Value:    value,
+			Prefix:   prefix,
+		}
+	}
+	parseError := ParserError{
+		Line:    operator.Line,
+		Message: "Invalid assignment target.",
+	}
+	errorMsg := parseError.Report(operator)
+	log.Print(errorMsg)
+	p.HadError = true
+	return target
+}
+
 // Utility Methods
 func (p *Parser) Previous() lexer.Token {
 	// retrieve the last emitted token
@@ -370,6 +1227,10 @@ func (p *Parser) Match(tokenTypeArgs []lexer.TokenType) bool {
 	}
 	return false
 }
+func (p *Parser) Check(tknType lexer.TokenType) bool {
+	// like Match but without consuming the token
+	return !p.IsAtEnd() && p.Peek().Type == tknType
+}
 func (p *Parser) Advance() lexer.Token {
 	if !p.IsAtEnd() {
 		p.Position++
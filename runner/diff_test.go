@@ -0,0 +1,27 @@
+package runner
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{"identical", "1\n", "1\n", ""},
+		{"changed line", "1\n2\n3\n", "1\n4\n3\n", "--- f.orig\n+++ f\n@@ -1,3 +1,3 @@\n 1\n-2\n+4\n 3\n"},
+		{"added newline", "1 + 2", "1 + 2\n", "--- f.orig\n+++ f\n@@ -1,1 +1,1 @@\n-1 + 2\n\\ No newline at end of file\n+1 + 2\n"},
+		{"changed line without newline", "1\n1+2", "1\n1 + 2\n", "--- f.orig\n+++ f\n@@ -1,2 +1,2 @@\n 1\n-1+2\n\\ No newline at end of file\n+1 + 2\n"},
+		{"removed newline", "1\n", "1", "--- f.orig\n+++ f\n@@ -1,1 +1,1 @@\n-1\n+1\n\\ No newline at end of file\n"},
+		{"to empty", "1\n", "", "--- f.orig\n+++ f\n@@ -1,1 +0,0 @@\n-1\n"},
+		{"from empty", "", "1\n", "--- f.orig\n+++ f\n@@ -0,0 +1,1 @@\n+1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnifiedDiff("f", tt.before, tt.after); got != tt.want {
+				t.Errorf("UnifiedDiff(%q, %q):\ngot  %q\nwant %q", tt.before, tt.after, got, tt.want)
+			}
+		})
+	}
+}
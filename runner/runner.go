@@ -6,6 +6,7 @@ import (
 
 	"github/goInterpreter/lexer"
 	"github/goInterpreter/parser"
+	"github/goInterpreter/parser/cst"
 	"github/goInterpreter/parser/exprVisitors"
 )
 
@@ -19,6 +20,10 @@ type ParserResult struct {
 	Error    error
 	ExitCode int
 }
+type FormatResult struct {
+	Source   string
+	ExitCode int
+}
 type EvaluateResult struct {
 	Result   string
 	Error    error
@@ -33,12 +38,30 @@ func (lr *LexerResult) Print() {
 	fmt.Print(res)
 	fmt.Fprint(os.Stderr, resErr)
 }
+
+// PrintErrors writes only the lexer errors, for the commands that stop at
+// them instead of printing the tokens
+func (lr *LexerResult) PrintErrors() {
+	fmt.Fprint(os.Stderr, lr.ErrorTok.ToString())
+}
 func (pr *ParserResult) Print() {
 	expr := pr.Expr
 	astp := exprVisitors.AstPrinter{}
 	astPrintInput := parser.TransformToStringAST(expr)
 	fmt.Println(astPrintInput.Accept(astp))
 }
+func (pr *ParserResult) PrintDot(annotate bool, numeric exprVisitors.NumericPolicy) {
+	dotp := exprVisitors.DotPrinter{
+		Annotate: annotate,
+		Interpreter: &exprVisitors.Interpreter{
+			Numeric: numeric,
+		},
+	}
+	fmt.Print(dotp.Print(pr.Expr))
+}
+func (fr *FormatResult) Print() {
+	fmt.Print(fr.Source)
+}
 func (er *EvaluateResult) Print() {
 	if er.Error != nil {
 		fmt.Fprint(os.Stderr, er.Error.Error())
@@ -76,9 +99,27 @@ func RunParser(tokens lexer.TokenizedText) ParserResult {
 		ExitCode: exitCode,
 	}
 }
-func RunEvaluator(expr exprVisitors.Expr[any, interface{}]) EvaluateResult {
+func RunFormatter(expr exprVisitors.Expr[any, interface{}], tokens lexer.TokenizedText) FormatResult {
+	tree, err := cst.Build(expr, tokens)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error occurred building syntax tree: %s", err.Error())
+		return FormatResult{
+			ExitCode: 70,
+		}
+	}
+	formatter := exprVisitors.Formatter{
+		Tokens: tree.Tokens(),
+		EOF:    tokens[len(tokens)-1],
+	}
+	return FormatResult{
+		Source:   formatter.Format(expr),
+		ExitCode: 0,
+	}
+}
+func RunEvaluator(expr exprVisitors.Expr[any, interface{}], numeric exprVisitors.NumericPolicy) EvaluateResult {
 	evaluator := exprVisitors.Interpreter{
 		HadError: false,
+		Numeric:  numeric,
 	}
 	evalRes := EvaluateResult{}
 	value := evaluator.Interpret(expr)